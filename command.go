@@ -0,0 +1,41 @@
+package orale
+
+import "strings"
+
+// Command is a subcommand selected by the first positional arguments, as in
+// `tool db migrate --dry-run`. The values of a command are scoped under a path
+// made from the names of the command and its parents, such as `db.migrate`, so
+// flags, environment variables, and configuration files can all set them.
+type Command struct {
+	// Name is the word which selects the command.
+	Name string
+	// Description is a short summary of the command shown in usage text.
+	Description string
+	// Target is the config struct for the command, usually the same pointer
+	// later passed to Get with the command's path. Flags given after the
+	// command which name a path of the target are scoped under the command's
+	// path, so `--dry-run` sets `db.migrate.dryRun`. Other flags are global,
+	// as are all flags of commands without a target.
+	Target any
+	// Commands are the subcommands of the command.
+	Commands []*Command
+}
+
+// commandPath converts the names of a command and its parents into the path
+// the command's values are scoped under.
+func commandPath(commandNames []string) string {
+	pathChunks := []string{}
+	for _, commandName := range commandNames {
+		pathChunks = append(pathChunks, flagNameToPath(commandName))
+	}
+	return strings.Join(pathChunks, ".")
+}
+
+func findCommand(commands []*Command, name string) *Command {
+	for _, command := range commands {
+		if command.Name == name {
+			return command
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package orale_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	type GlobalConfig struct {
+		Verbose bool `config:"verbose"`
+	}
+	type MigrateConfig struct {
+		DryRun bool   `config:"dryRun"`
+		Steps  int    `config:"steps"`
+		Url    string `config:"url"`
+		Target string `config:",arg=0,optional"`
+	}
+	type DbConfig struct {
+		Url string `config:"url"`
+	}
+
+	newCommands := func(dbConf *DbConfig, migrateConf *MigrateConfig) []*orale.Command {
+		return []*orale.Command{
+			{Name: "serve"},
+			{Name: "db", Target: dbConf, Commands: []*orale.Command{
+				{Name: "migrate", Target: migrateConf},
+			}},
+		}
+	}
+
+	t.Run("should select commands and scope their flags", func(t *testing.T) {
+		t.Parallel()
+
+		globalConf := GlobalConfig{}
+		dbConf := DbConfig{}
+		migrateConf := MigrateConfig{}
+		conf, err := orale.Load("tool",
+			orale.WithArgs([]string{"--verbose", "db", "--url", "postgres://db", "migrate", "--dry-run", "--steps", "3", "latest"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+			orale.WithTarget(&globalConf),
+			orale.WithCommands(newCommands(&dbConf, &migrateConf)...),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.CommandPath() != "db.migrate" {
+			t.Fatalf("expected command path to be db.migrate, got %s", conf.CommandPath())
+		}
+		if len(conf.Args) != 1 || conf.Args[0] != "latest" {
+			t.Fatalf("expected Args to be [latest], got %q", conf.Args)
+		}
+
+		conf.MustGetAll(&globalConf)
+		conf.MustGet("db", &dbConf)
+		conf.MustGet(conf.CommandPath(), &migrateConf)
+
+		if !globalConf.Verbose {
+			t.Fatal("expected Verbose to be true")
+		}
+		if dbConf.Url != "postgres://db" {
+			t.Fatalf("expected db url to be postgres://db, got %s", dbConf.Url)
+		}
+		if migrateConf.Url != "" {
+			t.Fatalf("expected db flags not to leak into migrate, got %s", migrateConf.Url)
+		}
+		if !migrateConf.DryRun {
+			t.Fatal("expected DryRun to be true")
+		}
+		if migrateConf.Steps != 3 {
+			t.Fatalf("expected Steps to be 3, got %d", migrateConf.Steps)
+		}
+		if migrateConf.Target != "latest" {
+			t.Fatalf("expected Target to be latest, got %s", migrateConf.Target)
+		}
+	})
+
+	t.Run("should accept global flags anywhere and command values from other sources", func(t *testing.T) {
+		t.Parallel()
+
+		globalConf := GlobalConfig{}
+		dbConf := DbConfig{}
+		migrateConf := MigrateConfig{}
+		conf, err := orale.Load("tool",
+			orale.WithArgs([]string{"db", "migrate", "--verbose"}),
+			orale.WithEnviron([]string{"TOOL__DB__MIGRATE__STEPS=5"}),
+			orale.WithFS(fstest.MapFS{"tool.config.toml": {Data: []byte("[db.migrate]\ndry_run = true")}}),
+			orale.WithTarget(&globalConf),
+			orale.WithCommands(newCommands(&dbConf, &migrateConf)...),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conf.MustGetAll(&globalConf)
+		conf.MustGet(conf.CommandPath(), &migrateConf)
+
+		if !globalConf.Verbose {
+			t.Fatal("expected Verbose to be true")
+		}
+		if migrateConf.Steps != 5 {
+			t.Fatalf("expected Steps to be 5, got %d", migrateConf.Steps)
+		}
+		if !migrateConf.DryRun {
+			t.Fatal("expected DryRun to be true")
+		}
+	})
+
+	t.Run("should only select commands from the leading positional arguments", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.Load("tool",
+			orale.WithArgs([]string{"other", "serve"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+			orale.WithCommands(newCommands(&DbConfig{}, &MigrateConfig{})...),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.Command) != 0 {
+			t.Fatalf("expected no command, got %q", conf.Command)
+		}
+		if len(conf.Args) != 2 {
+			t.Fatalf("expected Args to be [other serve], got %q", conf.Args)
+		}
+	})
+}
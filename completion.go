@@ -0,0 +1,349 @@
+package orale
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const completionFlagName = "completion"
+
+// Shells Completion can generate scripts for.
+const (
+	BashShell = "bash"
+	ZshShell  = "zsh"
+	FishShell = "fish"
+)
+
+// ErrUnsupportedShell is returned by Completion for shells it can't generate
+// scripts for.
+var ErrUnsupportedShell = errors.New("unsupported shell")
+
+// completionFlag is a flag offered by a completion script. Its name excludes
+// leading dashes. Flags without a value are switches, and boolean switches may
+// be negated.
+type completionFlag struct {
+	name      string
+	desc      string
+	isSwitch  bool
+	negatable bool
+	values    []string
+}
+
+// completionLevel holds the flags and subcommands offered after the commands
+// named by path, which is empty at the top level.
+type completionLevel struct {
+	path     []string
+	flags    []completionFlag
+	commands []*Command
+}
+
+// Completion returns a script which completes the application's flags,
+// commands, and the values listed in `enum` tags for the given shell, which
+// is one of BashShell, ZshShell, or FishShell. Flags are derived from target,
+// which should be the same target passed to Get, and from the targets of the
+// commands given to WithCommands. Users install the script with their shell,
+// for example:
+//
+//	source <(my-app --completion=bash)
+//
+// The script is usually printed when CompletionShell is set.
+func (l *Loader) Completion(shell string, target any) (string, error) {
+	if l.name == "" {
+		return "", errors.New("completion requires the application name given to Load")
+	}
+
+	globalFlags := collectCompletionFlags("", target)
+	globalFlags = append(globalFlags,
+		completionFlag{name: helpFlagName, desc: "Show help", isSwitch: true},
+		completionFlag{name: completionFlagName, desc: "Print a shell completion script", values: []string{BashShell, ZshShell, FishShell}},
+	)
+	levels := collectCompletionLevels(nil, globalFlags, l.commands)
+
+	switch shell {
+	case BashShell:
+		return bashCompletion(l.name, levels), nil
+	case ZshShell:
+		return zshCompletion(l.name, levels), nil
+	case FishShell:
+		return fishCompletion(l.name, levels), nil
+	default:
+		return "", fmt.Errorf("%w %q, expected %s, %s, or %s", ErrUnsupportedShell, shell, BashShell, ZshShell, FishShell)
+	}
+}
+
+// collectCompletionFlags returns the flags which set the paths of target
+// scoped under path. The path is left out of flag names, as flags given after
+// a command are scoped under the command's path.
+func collectCompletionFlags(path string, target any) []completionFlag {
+	if target == nil {
+		return []completionFlag{}
+	}
+	flags := []usageFlag{}
+	collectUsage(path, reflect.ValueOf(target), reflect.StructField{}, &flags, &[]usageArg{}, map[reflect.Type]bool{})
+
+	completionFlags := []completionFlag{}
+	for _, flag := range flags {
+		flagPath := flag.path
+		if path != "" {
+			flagPath = strings.TrimPrefix(flagPath, path+".")
+		}
+		completionFlags = append(completionFlags, completionFlag{
+			name:      pathToFlagName(flagPath),
+			desc:      flag.desc,
+			isSwitch:  flag.isBool,
+			negatable: flag.isBool,
+			values:    flag.values,
+		})
+	}
+	return completionFlags
+}
+
+// collectCompletionLevels returns a level for the commands named by path and
+// each of their subcommands. Flags of a command remain available after its
+// subcommands.
+func collectCompletionLevels(path []string, flags []completionFlag, commands []*Command) []completionLevel {
+	levels := []completionLevel{{path: path, flags: flags, commands: commands}}
+	for _, command := range commands {
+		commandNames := append(append([]string{}, path...), command.Name)
+		commandFlags := append(collectCompletionFlags(commandPath(commandNames), command.Target), flags...)
+		levels = append(levels, collectCompletionLevels(commandNames, commandFlags, command.Commands)...)
+	}
+	return levels
+}
+
+// spellings returns the arguments which set the flag.
+func (f completionFlag) spellings() []string {
+	if len(f.name) == 1 {
+		return []string{"-" + f.name}
+	}
+	spellings := []string{"--" + f.name}
+	if f.negatable {
+		spellings = append(spellings, "--"+negatedFlagPrefix+f.name)
+	}
+	return spellings
+}
+
+// words returns the commands and flags offered at the level.
+func (l completionLevel) words() []string {
+	words := []string{}
+	for _, command := range l.commands {
+		words = append(words, command.Name)
+	}
+	for _, flag := range l.flags {
+		words = append(words, flag.spellings()...)
+	}
+	return words
+}
+
+// completionFunctionName converts an application name into the name of the
+// shell function a completion script defines.
+func completionFunctionName(name string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// shellQuote quotes s for bash and zsh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// writeCommandCases writes case clauses which track the selected command as
+// words are read, shared by the bash and zsh scripts.
+func writeCommandCases(script *strings.Builder, levels []completionLevel) {
+	for _, level := range levels {
+		for _, command := range level.commands {
+			commandNames := append(append([]string{}, level.path...), command.Name)
+			fmt.Fprintf(script, "            %s) command=%s ;;\n",
+				shellQuote(strings.Join(level.path, " ")+":"+command.Name),
+				shellQuote(strings.Join(commandNames, " ")))
+		}
+	}
+}
+
+// writeValueCases writes case clauses which offer the values of flags with an
+// `enum` tag, shared by the bash and zsh scripts. offer formats the command
+// offering the given values.
+func writeValueCases(script *strings.Builder, levels []completionLevel, offer func(values []string) string) {
+	for _, level := range levels {
+		for _, flag := range level.flags {
+			if len(flag.values) == 0 {
+				continue
+			}
+			fmt.Fprintf(script, "        %s) %s; return ;;\n",
+				shellQuote(strings.Join(level.path, " ")+":"+flag.spellings()[0]),
+				offer(flag.values))
+		}
+	}
+}
+
+func bashCompletion(name string, levels []completionLevel) string {
+	functionName := completionFunctionName(name)
+	script := &strings.Builder{}
+
+	fmt.Fprintf(script, "# bash completion for %s\n\n", name)
+	fmt.Fprintf(script, "%s() {\n", functionName)
+	script.WriteString(`    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local command="" i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "$command:${COMP_WORDS[i]}" in
+`)
+	writeCommandCases(script, levels)
+	script.WriteString(`        esac
+    done
+    if [[ "$cur" == "=" ]]; then
+        cur=""
+    elif [[ "$prev" == "=" ]]; then
+        prev="${COMP_WORDS[COMP_CWORD-2]}"
+    fi
+    case "$command:$prev" in
+`)
+	writeValueCases(script, levels, func(values []string) string {
+		return fmt.Sprintf(`COMPREPLY=($(compgen -W %s -- "$cur"))`, shellQuote(strings.Join(values, " ")))
+	})
+	script.WriteString(`    esac
+    case "$command" in
+`)
+	for _, level := range levels {
+		fmt.Fprintf(script, "        %s) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n",
+			shellQuote(strings.Join(level.path, " ")),
+			shellQuote(strings.Join(level.words(), " ")))
+	}
+	script.WriteString("    esac\n}\n\n")
+	fmt.Fprintf(script, "complete -o default -F %s %s\n", functionName, shellQuote(name))
+
+	return script.String()
+}
+
+func zshCompletion(name string, levels []completionLevel) string {
+	functionName := completionFunctionName(name)
+	script := &strings.Builder{}
+
+	fmt.Fprintf(script, "#compdef %s\n\n", name)
+	fmt.Fprintf(script, "%s() {\n", functionName)
+	script.WriteString(`    local command="" i
+    for ((i = 2; i < CURRENT; i++)); do
+        case "$command:${words[i]}" in
+`)
+	writeCommandCases(script, levels)
+	script.WriteString(`        esac
+    done
+    local prev="${words[CURRENT-1]}"
+    if [[ "${words[CURRENT]}" == -*=* ]]; then
+        prev="${words[CURRENT]%%=*}"
+        compset -P '*='
+    fi
+    case "$command:$prev" in
+`)
+	writeValueCases(script, levels, func(values []string) string {
+		quotedValues := []string{}
+		for _, value := range values {
+			quotedValues = append(quotedValues, shellQuote(value))
+		}
+		return "compadd -- " + strings.Join(quotedValues, " ")
+	})
+	script.WriteString(`    esac
+    if [[ "${words[CURRENT]}" == -*=* ]]; then
+        _files
+        return
+    fi
+    case "$command" in
+`)
+	for _, level := range levels {
+		quotedWords := []string{}
+		for _, word := range level.words() {
+			quotedWords = append(quotedWords, shellQuote(word))
+		}
+		fmt.Fprintf(script, "        %s) compadd -- %s ;;\n",
+			shellQuote(strings.Join(level.path, " ")),
+			strings.Join(quotedWords, " "))
+	}
+	script.WriteString(`    esac
+    if [[ "${words[CURRENT]}" != -* ]]; then
+        _files
+    fi
+}
+
+`)
+	fmt.Fprintf(script, "if [[ \"${funcstack[1]}\" == %s ]]; then\n", functionName)
+	fmt.Fprintf(script, "    %s \"$@\"\nelse\n", functionName)
+	fmt.Fprintf(script, "    compdef %s %s\nfi\n", functionName, shellQuote(name))
+
+	return script.String()
+}
+
+func fishCompletion(name string, levels []completionLevel) string {
+	functionName := strings.TrimPrefix(completionFunctionName(name), "_")
+	commandFunctionName := "__" + functionName + "_command"
+	script := &strings.Builder{}
+
+	fmt.Fprintf(script, "# fish completion for %s\n\n", name)
+
+	// The command function prints the selected command prefixed with a
+	// colon, so the top level is never an empty string.
+	fmt.Fprintf(script, "function %s\n", commandFunctionName)
+	script.WriteString(`    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    set -l command ""
+    for token in $tokens
+        switch "$command:$token"
+`)
+	for _, level := range levels {
+		for _, command := range level.commands {
+			commandNames := append(append([]string{}, level.path...), command.Name)
+			fmt.Fprintf(script, "            case %s\n", fishQuote(strings.Join(level.path, " ")+":"+command.Name))
+			fmt.Fprintf(script, "                set command %s\n", fishQuote(strings.Join(commandNames, " ")))
+		}
+	}
+	script.WriteString(`        end
+    end
+    echo ":$command"
+end
+
+`)
+
+	for _, level := range levels {
+		condition := fishQuote(fmt.Sprintf("test (%s) = %s", commandFunctionName, fishQuote(":"+strings.Join(level.path, " "))))
+		for _, command := range level.commands {
+			line := fmt.Sprintf("complete -c %s -n %s -f -a %s", fishQuote(name), condition, fishQuote(command.Name))
+			if command.Description != "" {
+				line += " -d " + fishQuote(command.Description)
+			}
+			fmt.Fprintln(script, line)
+		}
+		for _, flag := range level.flags {
+			line := fmt.Sprintf("complete -c %s -n %s", fishQuote(name), condition)
+			if len(flag.name) == 1 {
+				line += " -s " + fishQuote(flag.name)
+			} else {
+				line += " -l " + fishQuote(flag.name)
+			}
+			switch {
+			case len(flag.values) != 0:
+				line += " -x -a " + fishQuote(strings.Join(flag.values, " "))
+			case !flag.isSwitch:
+				line += " -r"
+			}
+			if flag.desc != "" {
+				line += " -d " + fishQuote(flag.desc)
+			}
+			fmt.Fprintln(script, line)
+			if flag.negatable && len(flag.name) != 1 {
+				fmt.Fprintf(script, "complete -c %s -n %s -l %s\n", fishQuote(name), condition, fishQuote(negatedFlagPrefix+flag.name))
+			}
+		}
+	}
+
+	return script.String()
+}
@@ -0,0 +1,145 @@
+package orale_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestCompletion(t *testing.T) {
+	t.Parallel()
+
+	type MigrateConfig struct {
+		DryRun bool   `config:"dryRun" desc:"Print the migrations without applying them"`
+		Mode   string `config:"mode" enum:"up,down"`
+	}
+	type Config struct {
+		Verbose  bool   `config:"verbose" desc:"Log more"`
+		LogLevel string `config:"logLevel" desc:"Log level" enum:"debug,info,warn"`
+		Db       struct {
+			ConnectionPoolSize int `config:"connectionPoolSize"`
+		} `config:"db"`
+	}
+
+	load := func(t *testing.T, args []string, target any) *orale.Loader {
+		loader, err := orale.Load("myApp",
+			orale.WithArgs(args),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+			orale.WithTarget(target),
+			orale.WithCommands(
+				&orale.Command{Name: "serve", Description: "Start the server"},
+				&orale.Command{Name: "db", Commands: []*orale.Command{
+					{Name: "migrate", Description: "Apply migrations", Target: &MigrateConfig{}},
+				}},
+			),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return loader
+	}
+
+	t.Run("should intercept the completion flag", func(t *testing.T) {
+		t.Parallel()
+
+		loader := load(t, []string{"--completion=zsh"}, &Config{})
+		if loader.CompletionShell != "zsh" {
+			t.Fatalf("expected zsh, got %q", loader.CompletionShell)
+		}
+		if len(loader.FlagValues()) != 0 {
+			t.Fatalf("expected --completion not to be a flag value, got %v", loader.FlagValues())
+		}
+
+		loader = load(t, []string{"--completion", "fish", "--verbose"}, &Config{})
+		if loader.CompletionShell != "fish" {
+			t.Fatalf("expected fish, got %q", loader.CompletionShell)
+		}
+		if loader.FlagValues()["verbose"][0] != "true" {
+			t.Fatalf("expected verbose to be true, got %v", loader.FlagValues()["verbose"])
+		}
+	})
+
+	t.Run("should require a shell for the completion flag", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := orale.Load("myApp",
+			orale.WithArgs([]string{"--completion"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+		)
+		if !errors.Is(err, orale.ErrMissingFlagValue) {
+			t.Fatalf("expected ErrMissingFlagValue, got %v", err)
+		}
+	})
+
+	t.Run("should not intercept a completion flag the target defines", func(t *testing.T) {
+		t.Parallel()
+
+		type CompletionConfig struct {
+			Completion string `config:"completion"`
+		}
+
+		loader := load(t, []string{"--completion=all"}, &CompletionConfig{})
+		if loader.CompletionShell != "" {
+			t.Fatalf("expected no completion shell, got %q", loader.CompletionShell)
+		}
+		if loader.FlagValues()["completion"][0] != "all" {
+			t.Fatalf("expected completion to be all, got %v", loader.FlagValues()["completion"])
+		}
+	})
+
+	t.Run("should offer flags, commands, and enum values", func(t *testing.T) {
+		t.Parallel()
+
+		loader := load(t, []string{}, &Config{})
+		for _, shell := range []string{orale.BashShell, orale.ZshShell, orale.FishShell} {
+			script, err := loader.Completion(shell, &Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, expected := range []string{"verbose", "no-verbose", "db--connection-pool-size", "log-level", "debug", "serve", "migrate", "dry-run", "up", "completion"} {
+				if !strings.Contains(script, expected) {
+					t.Fatalf("expected %s script to contain %s, got:\n%s", shell, expected, script)
+				}
+			}
+		}
+	})
+
+	t.Run("should scope command flags in bash scripts", func(t *testing.T) {
+		t.Parallel()
+
+		loader := load(t, []string{}, &Config{})
+		script, err := loader.Completion(orale.BashShell, &Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, expected := range []string{
+			`'db:migrate') command='db migrate' ;;`,
+			`':--log-level') COMPREPLY=($(compgen -W 'debug info warn' -- "$cur")); return ;;`,
+			`'db migrate:--mode') COMPREPLY=($(compgen -W 'up down' -- "$cur")); return ;;`,
+			`'') COMPREPLY=($(compgen -W 'serve db --verbose --no-verbose --log-level --db--connection-pool-size --help --completion' -- "$cur")) ;;`,
+			`complete -o default -F _my_app 'my-app'`,
+		} {
+			if !strings.Contains(script, expected) {
+				t.Fatalf("expected script to contain %s, got:\n%s", expected, script)
+			}
+		}
+		if strings.Contains(script, `'':--mode`) || strings.Contains(script, `':--mode'`) {
+			t.Fatalf("expected --mode to be offered only after db migrate, got:\n%s", script)
+		}
+	})
+
+	t.Run("should return an error for unsupported shells", func(t *testing.T) {
+		t.Parallel()
+
+		loader := load(t, []string{}, &Config{})
+		if _, err := loader.Completion("powershell", &Config{}); !errors.Is(err, orale.ErrUnsupportedShell) {
+			t.Fatalf("expected ErrUnsupportedShell, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,256 @@
+package orale
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const dotenvFileName = ".env"
+
+// loadDotenvFiles searches the given directories for `.env` files,
+// as well as a `.env.<environment>` file for each environment name given. The
+// variables they contain are mapped into paths the same way as environment
+// variables. Files in earlier directories take precedence over files in later
+// ones, environment specific files take precedence over `.env`, and later
+// environments take precedence over earlier ones.
+// References such as `${VAR}` are resolved against envVars first, then against
+// variables defined earlier in the same file, then against variables defined
+// by dotenv files of lower precedence.
+func loadDotenvFiles(fileSystem fileSystem, environmentNames []string, searchPaths []string, variablePrefix string, envVars []string) (map[string][]any, error) {
+	fileNames := []string{}
+	for i := len(environmentNames) - 1; i >= 0; i -= 1 {
+		fileNames = append(fileNames, dotenvFileName+"."+environmentNames[i])
+	}
+	fileNames = append(fileNames, dotenvFileName)
+
+	filePaths := []string{}
+	for _, currentPath := range searchPaths {
+		for _, fileName := range fileNames {
+			filePaths = append(filePaths, fileSystem.join(currentPath, fileName))
+		}
+	}
+
+	processVariables := environMap(envVars)
+
+	dotenvVariables := map[string]string{}
+	dotenvKeys := []string{}
+	lookupProcess := func(key string) (string, bool) {
+		value, ok := processVariables[key]
+		return value, ok
+	}
+	lookupDotenv := func(key string) (string, bool) {
+		value, ok := dotenvVariables[key]
+		return value, ok
+	}
+
+	// Files are parsed from lowest to highest precedence so later assignments
+	// override earlier ones, and references can see inherited values.
+	for i := len(filePaths) - 1; i >= 0; i -= 1 {
+		fileBytes, err := fileSystem.readFile(filePaths[i])
+		if err != nil {
+			if isMissingFileErr(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		entries, err := parseDotenv(string(fileBytes), lookupProcess, lookupDotenv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filePaths[i], err)
+		}
+		for _, entry := range entries {
+			if _, ok := dotenvVariables[entry[0]]; !ok {
+				dotenvKeys = append(dotenvKeys, entry[0])
+			}
+			dotenvVariables[entry[0]] = entry[1]
+		}
+	}
+
+	dotenvEnvVars := []string{}
+	for _, key := range dotenvKeys {
+		dotenvEnvVars = append(dotenvEnvVars, key+"="+dotenvVariables[key])
+	}
+
+	return loadEnvironment(variablePrefix, dotenvEnvVars), nil
+}
+
+// parseDotenv parses the contents of a dotenv file into key value pairs in the
+// order they are defined. Lines may be prefixed with `export`, and values may
+// be unquoted, single quoted, double quoted, or backtick quoted. Quoted values
+// may span multiple lines. Double quoted values support escape sequences, and
+// both unquoted and double quoted values expand `$VAR`, `${VAR}`, and
+// `${VAR:-default}` references. References are resolved using lookup first,
+// then keys defined earlier in the content, then inherited.
+func parseDotenv(content string, lookup func(string) (string, bool), inherited func(string) (string, bool)) ([][2]string, error) {
+	entries := [][2]string{}
+	localVariables := map[string]string{}
+	localLookup := func(key string) (string, bool) {
+		if value, ok := lookup(key); ok {
+			return value, true
+		}
+		if value, ok := localVariables[key]; ok {
+			return value, true
+		}
+		return inherited(key)
+	}
+
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	line := 1
+	i := 0
+	for i < len(content) {
+		lineEnd := strings.IndexByte(content[i:], '\n')
+		if lineEnd == -1 {
+			lineEnd = len(content)
+		} else {
+			lineEnd += i
+		}
+
+		currentLine := strings.TrimSpace(content[i:lineEnd])
+		if currentLine == "" || currentLine[0] == '#' {
+			i = lineEnd + 1
+			line += 1
+			continue
+		}
+
+		for i < lineEnd && unicode.IsSpace(rune(content[i])) {
+			i += 1
+		}
+		if strings.HasPrefix(content[i:lineEnd], "export ") || strings.HasPrefix(content[i:lineEnd], "export\t") {
+			i += len("export")
+		}
+
+		splitIndex := strings.IndexByte(content[i:lineEnd], '=')
+		if splitIndex == -1 {
+			return nil, fmt.Errorf("line %d: expected KEY=value", line)
+		}
+		key := strings.TrimSpace(content[i : i+splitIndex])
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: invalid key %q", line, key)
+		}
+		i += splitIndex + 1
+		for i < lineEnd && (content[i] == ' ' || content[i] == '\t') {
+			i += 1
+		}
+
+		var value string
+		startLine := line
+		if i < lineEnd && (content[i] == '"' || content[i] == '\'' || content[i] == '`') {
+			quote := content[i]
+			i += 1
+			valueBuilder := strings.Builder{}
+			closed := false
+			for i < len(content) {
+				currentChar := content[i]
+				if currentChar == '\n' {
+					line += 1
+				}
+				if quote == '"' && currentChar == '\\' && i+1 < len(content) {
+					switch content[i+1] {
+					case 'n':
+						valueBuilder.WriteByte('\n')
+					case 'r':
+						valueBuilder.WriteByte('\r')
+					case 't':
+						valueBuilder.WriteByte('\t')
+					case '$':
+						// Escaped dollar signs are protected from expansion below.
+						valueBuilder.WriteString("\\$")
+					default:
+						valueBuilder.WriteByte(content[i+1])
+					}
+					i += 2
+					continue
+				}
+				if currentChar == quote {
+					closed = true
+					i += 1
+					break
+				}
+				valueBuilder.WriteByte(currentChar)
+				i += 1
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", startLine)
+			}
+			value = valueBuilder.String()
+			if quote == '"' {
+				value = expandDotenvValue(value, localLookup)
+			}
+
+			lineEnd = strings.IndexByte(content[i:], '\n')
+			if lineEnd == -1 {
+				lineEnd = len(content)
+			} else {
+				lineEnd += i
+			}
+			remaining := strings.TrimSpace(content[i:lineEnd])
+			if remaining != "" && remaining[0] != '#' {
+				return nil, fmt.Errorf("line %d: unexpected characters after quoted value", line)
+			}
+		} else {
+			value = content[i:lineEnd]
+			for j := 0; j < len(value); j += 1 {
+				if value[j] == '#' && (j == 0 || value[j-1] == ' ' || value[j-1] == '\t') {
+					value = value[:j]
+					break
+				}
+			}
+			value = expandDotenvValue(strings.TrimSpace(value), localLookup)
+		}
+
+		localVariables[key] = value
+		entries = append(entries, [2]string{key, value})
+
+		i = lineEnd + 1
+		line += 1
+	}
+
+	return entries, nil
+}
+
+func expandDotenvValue(value string, lookup func(string) (string, bool)) string {
+	expandedValue := strings.Builder{}
+	for i := 0; i < len(value); i += 1 {
+		currentChar := value[i]
+		if currentChar == '\\' && i+1 < len(value) && value[i+1] == '$' {
+			expandedValue.WriteByte('$')
+			i += 1
+			continue
+		}
+		if currentChar != '$' || i+1 >= len(value) {
+			expandedValue.WriteByte(currentChar)
+			continue
+		}
+
+		if value[i+1] == '{' {
+			endIndex := strings.IndexByte(value[i+2:], '}')
+			if endIndex == -1 {
+				expandedValue.WriteByte(currentChar)
+				continue
+			}
+			reference := value[i+2 : i+2+endIndex]
+			name, defaultValue, hasDefault := strings.Cut(reference, ":-")
+			if referencedValue, ok := lookup(name); ok && (referencedValue != "" || !hasDefault) {
+				expandedValue.WriteString(referencedValue)
+			} else {
+				expandedValue.WriteString(defaultValue)
+			}
+			i += 2 + endIndex
+			continue
+		}
+
+		nameEnd := i + 1
+		for nameEnd < len(value) && (value[nameEnd] == '_' || unicode.IsLetter(rune(value[nameEnd])) || unicode.IsDigit(rune(value[nameEnd]))) {
+			nameEnd += 1
+		}
+		if nameEnd == i+1 {
+			expandedValue.WriteByte(currentChar)
+			continue
+		}
+		referencedValue, _ := lookup(value[i+1 : nameEnd])
+		expandedValue.WriteString(referencedValue)
+		i = nameEnd - 1
+	}
+	return expandedValue.String()
+}
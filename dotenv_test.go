@@ -0,0 +1,196 @@
+package orale_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestDotenv(t *testing.T) {
+	t.Parallel()
+
+	fileSystem := fstest.MapFS{
+		".env": {Data: []byte(`
+APP__ROOT=root
+APP__SHARED=root
+`)},
+		"project/.env": {Data: []byte(`# A comment
+export APP__UNQUOTED=  plain value # inline comment
+APP__SINGLE='single $HOME \n'
+APP__DOUBLE="double\tquoted \"value\" from $HOME"
+APP__MULTI="line one
+line two"
+APP__REFERENCE=${APP__ROOT}-${MISSING:-fallback}-\${HOME}
+APP__SHARED=project
+APP__OVERRIDDEN=dotenv
+APP__DB__PORT=5432
+`)},
+		"project/.env.test": {Data: []byte(`APP__SHARED=test`)},
+	}
+
+	t.Run("should load dotenv files and map keys like environment variables", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Root       string `config:"root"`
+			Shared     string `config:"shared"`
+			Unquoted   string `config:"unquoted"`
+			Single     string `config:"single"`
+			Double     string `config:"double"`
+			Multi      string `config:"multi"`
+			Reference  string `config:"reference"`
+			Overridden string `config:"overridden"`
+			Db         struct {
+				Port int `config:"port"`
+			} `config:"db"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{"HOME=/home/app", "APP__OVERRIDDEN=environment"}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+			orale.WithDotenv(true),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Root != "root" {
+			t.Fatalf("expected Root to be root, got %q", testConf.Root)
+		}
+		if testConf.Shared != "project" {
+			t.Fatalf("expected Shared to be project, got %q", testConf.Shared)
+		}
+		if testConf.Unquoted != "plain value" {
+			t.Fatalf("expected Unquoted to be plain value, got %q", testConf.Unquoted)
+		}
+		if testConf.Single != "single $HOME \\n" {
+			t.Fatalf("expected Single to be unexpanded, got %q", testConf.Single)
+		}
+		if testConf.Double != "double\tquoted \"value\" from /home/app" {
+			t.Fatalf("expected Double to be expanded, got %q", testConf.Double)
+		}
+		if testConf.Multi != "line one\nline two" {
+			t.Fatalf("expected Multi to span two lines, got %q", testConf.Multi)
+		}
+		if testConf.Reference != "root-fallback-${HOME}" {
+			t.Fatalf("expected Reference to be root-fallback-${HOME}, got %q", testConf.Reference)
+		}
+		if testConf.Overridden != "environment" {
+			t.Fatalf("expected Overridden to be environment, got %q", testConf.Overridden)
+		}
+		if testConf.Db.Port != 5432 {
+			t.Fatalf("expected Db.Port to be 5432, got %d", testConf.Db.Port)
+		}
+	})
+
+	t.Run("should prefer environment specific dotenv files", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Shared string `config:"shared"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-environment=test"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+			orale.WithDotenv(true),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Shared != "test" {
+			t.Fatalf("expected Shared to be test, got %q", testConf.Shared)
+		}
+	})
+
+	t.Run("should resolve references against the environment before the dotenv file", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			FromEnv  string `config:"fromEnv"`
+			FromFile string `config:"fromFile"`
+			FromRoot string `config:"fromRoot"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{"FOO=proc"}),
+			orale.WithFS(fstest.MapFS{
+				".env":         {Data: []byte("BAR=root\nBAZ=root")},
+				"project/.env": {Data: []byte("FOO=file\nBAR=file\nAPP__FROM_ENV=${FOO}\nAPP__FROM_FILE=${BAR}\nAPP__FROM_ROOT=${BAZ}")},
+			}),
+			orale.WithWorkingDir("project"),
+			orale.WithDotenv(true),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.FromEnv != "proc" {
+			t.Fatalf("expected FromEnv to be proc, got %q", testConf.FromEnv)
+		}
+		if testConf.FromFile != "file" {
+			t.Fatalf("expected FromFile to be file, got %q", testConf.FromFile)
+		}
+		if testConf.FromRoot != "root" {
+			t.Fatalf("expected FromRoot to be root, got %q", testConf.FromRoot)
+		}
+	})
+
+	t.Run("should not load dotenv files unless enabled", func(t *testing.T) {
+		t.Parallel()
+
+		for _, opts := range [][]orale.LoadOption{{}, {orale.WithDotenv(false)}} {
+			conf, err := orale.Load("app", append([]orale.LoadOption{
+				orale.WithArgs([]string{}),
+				orale.WithEnviron([]string{}),
+				orale.WithFS(fileSystem),
+				orale.WithWorkingDir("project"),
+			}, opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(conf.DotenvValues()) != 0 {
+				t.Fatalf("expected no dotenv values, got %v", conf.DotenvValues())
+			}
+		}
+
+		conf, err := orale.LoadFromValues([]string{}, "APP", []string{}, "project", []string{"app"}, orale.WithFS(fileSystem))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(conf.DotenvValues()) != 0 {
+			t.Fatalf("expected no dotenv values from LoadFromValues, got %v", conf.DotenvValues())
+		}
+	})
+
+	t.Run("should report unterminated quoted values", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{".env": {Data: []byte("APP__A=\"unterminated\n")}}),
+			orale.WithDotenv(true),
+		)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
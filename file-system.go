@@ -0,0 +1,118 @@
+package orale
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fileSystem provides file access either to the operating system's file system
+// or, when fsys is set, to an fs.FS.
+type fileSystem struct {
+	fsys fs.FS
+}
+
+func (f fileSystem) readFile(name string) ([]byte, error) {
+	if f.fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(f.fsys, name)
+}
+
+// readDir returns the entries of a directory sorted by name.
+func (f fileSystem) readDir(name string) ([]fs.DirEntry, error) {
+	if f.fsys == nil {
+		return os.ReadDir(name)
+	}
+	return fs.ReadDir(f.fsys, name)
+}
+
+func (f fileSystem) stat(name string) (fs.FileInfo, error) {
+	if f.fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(f.fsys, name)
+}
+
+func (f fileSystem) exists(name string) bool {
+	_, err := f.stat(name)
+	return err == nil
+}
+
+// join joins path elements. Within an fs.FS the result is always unrooted, so
+// absolute paths such as a home directory are taken to be relative to the root
+// of the file system.
+func (f fileSystem) join(elem ...string) string {
+	if f.fsys == nil {
+		return filepath.Join(elem...)
+	}
+	joinedPath := strings.TrimPrefix(path.Join(elem...), "/")
+	if joinedPath == "" {
+		return "."
+	}
+	return joinedPath
+}
+
+func (f fileSystem) dir(name string) string {
+	if f.fsys == nil {
+		return filepath.Dir(name)
+	}
+	return path.Dir(name)
+}
+
+// glob returns the paths matching pattern in lexical order.
+func (f fileSystem) glob(pattern string) ([]string, error) {
+	if f.fsys == nil {
+		return filepath.Glob(pattern)
+	}
+	return fs.Glob(f.fsys, pattern)
+}
+
+// resolve returns name unchanged if it is absolute, otherwise it is joined to
+// basePath. Paths within an fs.FS starting with a slash are taken to be
+// relative to the root of the file system.
+func (f fileSystem) resolve(basePath string, name string) string {
+	if f.fsys == nil {
+		if filepath.IsAbs(name) {
+			return name
+		}
+		return filepath.Join(basePath, name)
+	}
+	if strings.HasPrefix(name, "/") {
+		return path.Clean(strings.TrimPrefix(name, "/"))
+	}
+	return path.Join(basePath, name)
+}
+
+// searchPaths returns startPath followed by each of its parent directories.
+func (f fileSystem) searchPaths(startPath string) []string {
+	if f.fsys != nil {
+		currentPath := path.Clean(strings.TrimPrefix(startPath, "/"))
+		searchPaths := []string{currentPath}
+		for currentPath != "." {
+			currentPath = path.Dir(currentPath)
+			searchPaths = append(searchPaths, currentPath)
+		}
+		return searchPaths
+	}
+
+	currentPathChunks := strings.Split(startPath, string(filepath.Separator))
+
+	searchPaths := []string{}
+	for i := len(currentPathChunks); i > 0; i -= 1 {
+		isAbsPath := currentPathChunks[0] == ""
+		currentPath := filepath.Join(currentPathChunks[:i]...)
+		if isAbsPath {
+			currentPath = string(filepath.Separator) + currentPath
+		}
+		searchPaths = append(searchPaths, currentPath)
+	}
+	return searchPaths
+}
+
+func isMissingFileErr(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission)
+}
@@ -2,13 +2,13 @@ package orale
 
 import (
 	"fmt"
-	"os"
+	"io"
 	"strings"
-
-	"github.com/BurntSushi/toml"
 )
 
-// File represents a configuration file loaded from disk.
+// File represents a configuration file loaded from disk. Files may be written
+// in toml, yaml, json, json with comments (jsonc), ini, or Java properties, and
+// the format is selected by the file's extension.
 type File struct {
 	// Path is the absolute path to the configuration file.
 	Path string
@@ -17,34 +17,148 @@ type File struct {
 	// are represented by square brackets with the index inside. The value is
 	// always a slice of any. It's a slice because theoretically a configuration
 	// file could have multiple values for the same path. This is not the case with
-	// toml so as of now it's always a slice of length 1.
+	// any of the supported formats so as of now it's always a slice of length 1.
 	Values map[string][]any
+	// IncludedBy is the path of the configuration file which included this one
+	// with an `include` or `extends` directive. It is empty for files which were
+	// not included.
+	IncludedBy string
+
+	priority int
+}
+
+var _ Source = &File{}
+
+// Name returns the path of the file.
+func (f *File) Name() string {
+	return f.Path
+}
+
+// Priority returns the priority of the file. Files default to FilePriority.
+func (f *File) Priority() int {
+	return f.priority
+}
+
+// FlatValues returns the flattened values of the file.
+func (f *File) FlatValues() map[string][]any {
+	return f.Values
+}
+
+// FileFromBytes decodes a configuration document into a File with the given
+// path. The format is a format name or extension such as `toml`, `yaml`, or
+// `json`. If format is empty it is selected by the path's extension. Profiles
+// are not applied, and since the document is not read from a file system,
+// include directives are an error. The file can be added to a loader with
+// WithSources.
+func FileFromBytes(filePath string, format string, data []byte) (*File, error) {
+	fileFormat := fileFormatFromPath(filePath)
+	if format != "" {
+		fileFormat = fileFormatFromName(format)
+	}
+	if fileFormat == nil {
+		if format == "" {
+			format = filePath
+		}
+		return nil, fmt.Errorf("unsupported configuration file format: %s", format)
+	}
+
+	configFile, includePatterns, err := decodeFile(nil, filePath, fileFormat, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(includePatterns) != 0 {
+		return nil, fmt.Errorf("invalid include in %s: includes are not supported when loading from bytes", filePath)
+	}
+	configFile.priority = FilePriority
+	return configFile, nil
 }
 
-func maybeLoadFile(maybeConfigFilePath string) (*File, error) {
-	fileBytes, err := os.ReadFile(maybeConfigFilePath)
+// LoadReader reads a configuration document from r and decodes it into a File
+// as described by FileFromBytes. The file's path is empty, and may be set by
+// the caller.
+func LoadReader(format string, r io.Reader) (*File, error) {
+	data, err := io.ReadAll(r)
 	if err != nil {
-		switch {
-		case os.IsNotExist(err), os.IsPermission(err):
+		return nil, err
+	}
+	return FileFromBytes("", format, data)
+}
+
+// maybeLoadFile loads a configuration file and the files it includes. If the
+// file does not exist nil is returned. See loadFile.
+func maybeLoadFile(fileSystem fileSystem, environmentNames []string, maybeConfigFilePath string) ([]*File, error) {
+	fileBytes, err := fileSystem.readFile(maybeConfigFilePath)
+	if err != nil {
+		if isMissingFileErr(err) {
 			return nil, nil
-		default:
-			return nil, err
 		}
+		return nil, err
 	}
+	return loadFileBytes(fileSystem, environmentNames, maybeConfigFilePath, fileBytes, nil)
+}
+
+// loadFile loads a configuration file followed by the files it includes in
+// order of precedence. Profiles within the files matching environmentNames are
+// applied.
+func loadFile(fileSystem fileSystem, environmentNames []string, configFilePath string) ([]*File, error) {
+	return loadIncludedFile(fileSystem, environmentNames, configFilePath, nil)
+}
 
-	fileStr := string(fileBytes)
+func loadIncludedFile(fileSystem fileSystem, environmentNames []string, configFilePath string, includeStack []string) ([]*File, error) {
+	fileBytes, err := fileSystem.readFile(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return loadFileBytes(fileSystem, environmentNames, configFilePath, fileBytes, includeStack)
+}
+
+func loadFileBytes(fileSystem fileSystem, environmentNames []string, configFilePath string, fileBytes []byte, includeStack []string) ([]*File, error) {
+	format := fileFormatFromPath(configFilePath)
+	if format == nil {
+		return nil, fmt.Errorf("unsupported configuration file format: %s", configFilePath)
+	}
+	return loadFormattedFileBytes(fileSystem, environmentNames, configFilePath, format, fileBytes, includeStack)
+}
+
+func loadFormattedFileBytes(fileSystem fileSystem, environmentNames []string, configFilePath string, format *fileFormat, fileBytes []byte, includeStack []string) ([]*File, error) {
+	configFile, includePatterns, err := decodeFile(environmentNames, configFilePath, format, fileBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(includeStack) != 0 {
+		configFile.IncludedBy = includeStack[len(includeStack)-1]
+	}
 
-	var hierarchicalFileValues map[string]any
-	if _, err := toml.Decode(fileStr, &hierarchicalFileValues); err != nil {
+	includedFiles, err := loadIncludes(fileSystem, environmentNames, configFilePath, includePatterns, includeStack)
+	if err != nil {
 		return nil, err
 	}
+
+	return append([]*File{configFile}, includedFiles...), nil
+}
+
+// decodeFile decodes the contents of a configuration file, applying the
+// profiles matching environmentNames. The include patterns found in the file
+// are returned alongside it.
+func decodeFile(environmentNames []string, configFilePath string, format *fileFormat, fileBytes []byte) (*File, []string, error) {
+	hierarchicalFileValues, err := format.decode(fileBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode %s: %w", configFilePath, err)
+	}
+	includePatterns, err := extractIncludePatterns(hierarchicalFileValues)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid include in %s: %w", configFilePath, err)
+	}
+	if err := applyProfiles(hierarchicalFileValues, environmentNames); err != nil {
+		return nil, nil, fmt.Errorf("invalid profiles in %s: %w", configFilePath, err)
+	}
 	fileValues := map[string][]any{}
 	flattenFileValues(nil, hierarchicalFileValues, fileValues)
 
 	return &File{
-		Path:   maybeConfigFilePath,
+		Path:   configFilePath,
 		Values: fileValues,
-	}, nil
+	}, includePatterns, nil
 }
 
 func flattenFileValues(pathChunks []string, hierarchicalValues map[string]any, flattenedValues map[string][]any) {
@@ -79,6 +193,10 @@ func flattenFileValues(pathChunks []string, hierarchicalValues map[string]any, f
 					}
 					subKeyPathChunks = append(subKeyPathChunks, chunk)
 				}
+				if subValues, ok := v.(map[string]any); ok {
+					flattenFileValues(subKeyPathChunks, subValues, flattenedValues)
+					continue
+				}
 				keyPath = strings.Join(subKeyPathChunks, ".")
 				if _, ok := flattenedValues[keyPath]; !ok {
 					flattenedValues[keyPath] = []any{}
@@ -87,6 +205,12 @@ func flattenFileValues(pathChunks []string, hierarchicalValues map[string]any, f
 			}
 		case map[string]any:
 			flattenFileValues(keyPathChunks, val, flattenedValues)
+		case listValue:
+			flattenedValues[keyPath] = append(flattenedValues[keyPath], val.value)
+			for i, item := range val.items {
+				itemPath := fmt.Sprintf("%s[%d]", keyPath, i)
+				flattenedValues[itemPath] = append(flattenedValues[itemPath], item)
+			}
 		default:
 			if _, ok := flattenedValues[keyPath]; !ok {
 				flattenedValues[keyPath] = []any{}
@@ -0,0 +1,124 @@
+package orale_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestFileFromBytes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should decode a document in the given format", func(t *testing.T) {
+		t.Parallel()
+
+		file, err := orale.FileFromBytes("vault", "yaml", []byte("db:\n  password: hunter2\n  hosts: [a, b]"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if file.Path != "vault" {
+			t.Fatalf("expected Path to be vault, got %s", file.Path)
+		}
+		if file.Priority() != orale.FilePriority {
+			t.Fatalf("expected priority to be %d, got %d", orale.FilePriority, file.Priority())
+		}
+		if file.Values["db.password"][0] != "hunter2" {
+			t.Fatalf("expected db.password to be hunter2, got %v", file.Values["db.password"])
+		}
+		if file.Values["db.hosts[1]"][0] != "b" {
+			t.Fatalf("expected db.hosts[1] to be b, got %v", file.Values["db.hosts[1]"])
+		}
+	})
+
+	t.Run("should select the format by the path's extension when no format is given", func(t *testing.T) {
+		t.Parallel()
+
+		file, err := orale.FileFromBytes("config.json", "", []byte(`{"port": 8080}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if file.Values["port"][0] != int64(8080) {
+			t.Fatalf("expected port to be 8080, got %#v", file.Values["port"])
+		}
+	})
+
+	t.Run("should preserve large json integers and accept empty json documents", func(t *testing.T) {
+		t.Parallel()
+
+		file, err := orale.FileFromBytes("config.json", "", []byte(`{"max": 18446744073709551615, "huge": 123456789012345678901234567890}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if file.Values["max"][0] != uint64(18446744073709551615) {
+			t.Fatalf("expected max to be uint64(18446744073709551615), got %#v", file.Values["max"][0])
+		}
+		if file.Values["huge"][0] != "123456789012345678901234567890" {
+			t.Fatalf("expected huge to keep its digits, got %#v", file.Values["huge"][0])
+		}
+
+		for _, format := range []string{"json", "jsonc"} {
+			file, err := orale.FileFromBytes("", format, []byte(" \n"))
+			if err != nil {
+				t.Fatalf("expected an empty %s document to load, got %v", format, err)
+			}
+			if len(file.Values) != 0 {
+				t.Fatalf("expected no values, got %v", file.Values)
+			}
+		}
+	})
+
+	t.Run("should return an error for unsupported formats and includes", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := orale.FileFromBytes("config.xml", "", []byte("<a/>")); err == nil {
+			t.Fatal("expected an error for an unsupported format")
+		}
+		if _, err := orale.FileFromBytes("", "toml", []byte(`include = ["other.toml"]`)); err == nil {
+			t.Fatal("expected an error for an include")
+		}
+	})
+}
+
+func TestLoadReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should read a document and add it to a loader as a source", func(t *testing.T) {
+		t.Parallel()
+
+		file, err := orale.LoadReader("toml", strings.NewReader("[server]\nport = 8080"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--server--host=localhost"}),
+			orale.WithEnviron([]string{}),
+			orale.WithWorkingDir(t.TempDir()),
+			orale.WithSources(file),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		type TestConfig struct {
+			Server struct {
+				Host string `config:"host"`
+				Port int    `config:"port"`
+			} `config:"server"`
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Server.Host != "localhost" {
+			t.Fatalf("expected Server.Host to be localhost, got %s", testConf.Server.Host)
+		}
+		if testConf.Server.Port != 8080 {
+			t.Fatalf("expected Server.Port to be 8080, got %d", testConf.Server.Port)
+		}
+	})
+}
@@ -0,0 +1,521 @@
+package orale
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+const flagTerminator = "--"
+const negatedFlagPrefix = "no-"
+const helpFlagName = "help"
+const shortHelpFlagName = "h"
+
+// Errors wrapped by FlagError describing why an argument could not be parsed.
+var (
+	ErrInvalidFlagName   = errors.New("invalid flag name")
+	ErrMissingFlagValue  = errors.New("missing flag value")
+	ErrInvalidFlagValue  = errors.New("invalid flag value")
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+	ErrResponseFile      = errors.New("invalid response file")
+)
+
+// FlagError is returned by Load and LoadFromValues when a program argument
+// can't be parsed as a flag. Err is one of ErrInvalidFlagName,
+// ErrMissingFlagValue, ErrInvalidFlagValue, ErrUnterminatedQuote, or
+// ErrResponseFile, possibly wrapped with more detail.
+type FlagError struct {
+	// Arg is the argument which could not be parsed.
+	Arg string
+	// Index is the position of the argument within the program arguments. For
+	// arguments read from a response file, it is the position of the `@file`
+	// argument.
+	Index int
+	// File and Line locate the argument within a response file. File is empty
+	// for arguments given directly.
+	File string
+	Line int
+	// Flag is the name of the flag within the argument, if known.
+	Flag string
+	// Err describes the problem.
+	Err error
+}
+
+func (e *FlagError) Error() string {
+	location := fmt.Sprintf("position %d", e.Index)
+	if e.File != "" {
+		location = fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+	if e.Flag == "" {
+		return fmt.Sprintf("invalid argument %q at %s: %s", e.Arg, location, e.Err)
+	}
+	return fmt.Sprintf("invalid flag %s in argument %q at %s: %s", e.Flag, e.Arg, location, e.Err)
+}
+
+func (e *FlagError) Unwrap() error {
+	return e.Err
+}
+
+// NOTE: programArgs should not include the program name - os.Args[1:]
+// would be appropriate
+//
+// loadFlags parses flags following POSIX and GNU conventions. Long flags may be
+// given as `--flag=value` or `--flag value`. Short flags may be given as
+// `-f=value`, `-f value`, or `-fvalue`, and may be combined, so `-abc` is the
+// same as `-a -b -c`. A flag without a value, such as `--verbose`, is set to
+// true, and a long flag prefixed with `no-`, such as `--no-verbose`, is set to
+// false. Values wrapped in single or double quotes are unquoted. Arguments
+// after `--` are not treated as flags, nor are a lone dash or negative numbers.
+// When singleDashLongFlags is set, `-flag` is a long flag rather than a group
+// of short flags. The origin of each value is returned alongside the values,
+// as are the positional arguments, which are the arguments that are neither
+// flags nor their values.
+//
+// `--help` and `-h` request help, and `--completion=<shell>` requests a
+// completion script, rather than setting a value, unless the target has a field
+// for them.
+//
+// Leading positional arguments naming one of commands, or one of the selected
+// command's subcommands, select that command instead. Flags given after a
+// command are scoped under the command's path when the target knows of the
+// scoped path.
+//
+// When responseFiles is given, `@path` arguments found in place of a flag or a
+// positional argument are replaced with the arguments read from the response
+// file. Arguments taken as a flag's value are never expanded.
+//
+// When the target's types are known, boolean flags never take the following
+// argument as their value, so `--verbose ./data` leaves `./data` as a
+// positional argument, and other flags must have a value. Otherwise a group of
+// short flags is taken to end with an attached value at the first character
+// which isn't a letter, as in `-p8080`.
+func loadFlags(programArgs []argument, target flagTarget, commands []*Command, singleDashLongFlags bool, responseFiles *responseFileReader) (parsedFlags, error) {
+	parser := &flagParser{
+		args:          programArgs,
+		target:        target,
+		commands:      commands,
+		responseFiles: responseFiles,
+		parsedFlags: parsedFlags{
+			values:         map[string][]any{},
+			origins:        map[string][]ArgumentOrigin{},
+			positionalArgs: []string{},
+			commandNames:   []string{},
+		},
+	}
+
+	for parser.index = 0; parser.index < len(parser.args); parser.index += 1 {
+		arg := parser.args[parser.index].value
+
+		var err error
+		switch {
+		case responseFiles != nil && isResponseFileArg(arg):
+			err = parser.expandResponseFile()
+		case arg == flagTerminator:
+			for _, positionalArg := range parser.args[parser.index+1:] {
+				parser.positionalArgs = append(parser.positionalArgs, positionalArg.value)
+			}
+			return parser.parsedFlags, nil
+		case parser.isHelp(arg, singleDashLongFlags):
+			parser.helpRequested = true
+		case strings.HasPrefix(arg, "--"):
+			err = parser.parseLongFlag(arg[2:])
+		case !strings.HasPrefix(arg, "-") || arg == "-" || isNumber(arg):
+			parser.addPositional(arg)
+		case singleDashLongFlags:
+			err = parser.parseLongFlag(arg[1:])
+		default:
+			err = parser.parseShortFlags(arg[1:])
+		}
+		if err != nil {
+			return parsedFlags{}, err
+		}
+	}
+
+	return parser.parsedFlags, nil
+}
+
+// parsedFlags holds the flag values parsed from the program arguments by path,
+// their origins, the positional arguments, and the names of the selected
+// command and its parents.
+type parsedFlags struct {
+	values          map[string][]any
+	origins         map[string][]ArgumentOrigin
+	positionalArgs  []string
+	commandNames    []string
+	helpRequested   bool
+	completionShell string
+}
+
+type flagParser struct {
+	parsedFlags
+	args          []argument
+	index         int
+	target        flagTarget
+	commands      []*Command
+	responseFiles *responseFileReader
+}
+
+// expandResponseFile replaces the current argument with the arguments read
+// from the response file it names, which are parsed next.
+func (p *flagParser) expandResponseFile() error {
+	fileArgs, err := p.responseFiles.read(p.args[p.index])
+	if err != nil {
+		return err
+	}
+	args := append(append([]argument{}, p.args[:p.index]...), fileArgs...)
+	p.args = append(args, p.args[p.index+1:]...)
+	p.index -= 1
+	return nil
+}
+
+// addPositional adds a positional argument, or selects a command if no
+// positional arguments have been given yet and arg names a command.
+func (p *flagParser) addPositional(arg string) {
+	if p.isCommand(arg) {
+		p.commandNames = append(p.commandNames, arg)
+		p.commands = findCommand(p.commands, arg).Commands
+		return
+	}
+	p.positionalArgs = append(p.positionalArgs, arg)
+}
+
+func (p *flagParser) isCommand(arg string) bool {
+	return len(p.positionalArgs) == 0 && findCommand(p.commands, arg) != nil
+}
+
+// isHelp reports whether arg requests help.
+func (p *flagParser) isHelp(arg string, singleDashLongFlags bool) bool {
+	var name string
+	switch {
+	case arg == "--"+helpFlagName || (singleDashLongFlags && arg == "-"+helpFlagName):
+		name = helpFlagName
+	case arg == "-"+shortHelpFlagName:
+		name = shortHelpFlagName
+	default:
+		return false
+	}
+	return !p.target.has(p.resolveKey(name))
+}
+
+// resolveKey scopes a flag's path under the innermost selected command whose
+// scoped path is known to the target. Otherwise the path is global.
+func (p *flagParser) resolveKey(name string) string {
+	key := flagNameToPath(name)
+	for i := len(p.commandNames); i > 0; i -= 1 {
+		scopedKey := commandPath(p.commandNames[:i]) + "." + key
+		if p.target.has(scopedKey) {
+			return scopedKey
+		}
+	}
+	return key
+}
+
+func (p *flagParser) parseLongFlag(body string) error {
+	name, value, hasValue := strings.Cut(body, "=")
+	if !isValidFlagName(name) {
+		return p.error(name, ErrInvalidFlagName)
+	}
+	key := p.resolveKey(name)
+
+	if name == completionFlagName && !p.target.has(key) {
+		return p.setCompletionShell(name, value, hasValue)
+	}
+	if hasValue {
+		return p.addValue(name, key, value)
+	}
+
+	// `--no-flag` negates a boolean flag the target knows of. Without a target,
+	// it is only taken as a negation when no value follows, so
+	// `--no-reply-address a@b` still sets `noReplyAddress`.
+	negatedName := strings.TrimPrefix(name, negatedFlagPrefix)
+	negatedKey := p.resolveKey(negatedName)
+	isNegated := strings.HasPrefix(name, negatedFlagPrefix) && isValidFlagName(negatedName) && !p.target.has(key) &&
+		(p.target.isBool(negatedKey) || (!p.target.known && !p.target.has(negatedKey) && !p.hasTrailingValue()))
+	if isNegated {
+		p.add(negatedKey, "false")
+		return nil
+	}
+
+	return p.addTrailingValue(name, key)
+}
+
+func (p *flagParser) parseShortFlags(body string) error {
+	// Without a target, `-port=80` would otherwise set p, o, and r to true and
+	// t to 80, which is almost certainly a long flag given Go style.
+	if group, _, hasValue := strings.Cut(body, "="); hasValue && !p.target.known && len([]rune(group)) > 1 && strings.IndexFunc(group, func(r rune) bool { return !unicode.IsLetter(r) }) == -1 {
+		return p.error(group, fmt.Errorf("%w: -%s is a group of short flags, long flags need two dashes or WithSingleDashLongFlags", ErrInvalidFlagName, group))
+	}
+
+	runes := []rune(body)
+	for i, r := range runes {
+		name := string(r)
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return p.error(name, ErrInvalidFlagName)
+		}
+		key := p.resolveKey(name)
+		rest := string(runes[i+1:])
+
+		switch {
+		case strings.HasPrefix(rest, "="):
+			return p.addValue(name, key, rest[1:])
+		case rest == "":
+			return p.addTrailingValue(name, key)
+		case p.target.isBool(key):
+			p.add(key, "true")
+		case p.target.has(key) || !unicode.IsLetter(runes[i+1]):
+			return p.addValue(name, key, rest)
+		case p.target.known:
+			return p.error(name, fmt.Errorf("%w: -%s is not a flag of the target, long flags need two dashes or WithSingleDashLongFlags", ErrInvalidFlagName, name))
+		default:
+			p.add(key, "true")
+		}
+	}
+	return nil
+}
+
+// addTrailingValue sets a flag given without an attached value, taking the
+// following argument as its value unless the flag is a boolean or the argument
+// names a command.
+func (p *flagParser) addTrailingValue(name string, key string) error {
+	if !p.target.isBool(key) && p.hasTrailingValue() {
+		p.index += 1
+		return p.addValue(name, key, p.args[p.index].value)
+	}
+	if p.target.has(key) && !p.target.isBool(key) {
+		return p.error(name, ErrMissingFlagValue)
+	}
+	p.add(key, "true")
+	return nil
+}
+
+// setCompletionShell records the shell a completion script was requested for,
+// taking the following argument as the shell if no value is attached.
+func (p *flagParser) setCompletionShell(name string, value string, hasValue bool) error {
+	if !hasValue {
+		if p.index+1 >= len(p.args) || !isFlagValue(p.args[p.index+1].value) {
+			return p.error(name, ErrMissingFlagValue)
+		}
+		p.index += 1
+		value = p.args[p.index].value
+	}
+	value, err := unquoteFlagValue(value)
+	if err != nil {
+		return p.error(name, err)
+	}
+	p.completionShell = value
+	return nil
+}
+
+// hasTrailingValue reports whether the argument following the current one can
+// be taken as its value.
+func (p *flagParser) hasTrailingValue() bool {
+	return p.index+1 < len(p.args) && isFlagValue(p.args[p.index+1].value) && !p.isCommand(p.args[p.index+1].value)
+}
+
+func (p *flagParser) addValue(name string, key string, value string) error {
+	value, err := unquoteFlagValue(value)
+	if err != nil {
+		return p.error(name, err)
+	}
+	if p.target.isBool(key) {
+		if _, ok := intoBool(value); !ok {
+			return p.error(name, fmt.Errorf("%w: %q is not a boolean", ErrInvalidFlagValue, value))
+		}
+	}
+	p.add(key, value)
+	return nil
+}
+
+func (p *flagParser) add(key string, value string) {
+	if _, ok := p.values[key]; !ok {
+		p.values[key] = []any{}
+	}
+	p.values[key] = append(p.values[key], value)
+	p.origins[key] = append(p.origins[key], p.args[p.index].origin)
+}
+
+func (p *flagParser) error(name string, err error) error {
+	arg := p.args[p.index]
+	return &FlagError{Arg: arg.value, Index: arg.origin.Index, File: arg.origin.File, Line: arg.origin.Line, Flag: name, Err: err}
+}
+
+// isValidFlagName reports whether name can be converted into a path. Each
+// segment between double dashes must be non empty, must not contain
+// whitespace, and must not start or end with a dash or underscore.
+func isValidFlagName(name string) bool {
+	for _, segment := range strings.Split(name, "--") {
+		if segment == "" || strings.IndexFunc(segment, unicode.IsSpace) != -1 {
+			return false
+		}
+		if strings.ContainsAny(segment[:1], "-_") || strings.ContainsAny(segment[len(segment)-1:], "-_") {
+			return false
+		}
+	}
+	return true
+}
+
+// unquoteFlagValue removes single or double quotes wrapping a value. Within
+// double quotes a backslash escapes the character following it.
+func unquoteFlagValue(value string) (string, error) {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return value, nil
+	}
+	quote := value[0]
+	if len(value) < 2 || value[len(value)-1] != quote {
+		return "", ErrUnterminatedQuote
+	}
+	value = value[1 : len(value)-1]
+	if quote == '\'' {
+		return value, nil
+	}
+
+	unquoted := strings.Builder{}
+	for i := 0; i < len(value); i += 1 {
+		if value[i] == '\\' {
+			if i+1 == len(value) {
+				return "", ErrUnterminatedQuote
+			}
+			i += 1
+		}
+		unquoted.WriteByte(value[i])
+	}
+	return unquoted.String(), nil
+}
+
+// flagNameToPath converts a flag name, without its leading dashes, into a
+// path. Double dashes separate path segments, and the segments are converted
+// to camel case.
+func flagNameToPath(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Replace(name, ".", "\\.", -1)
+	name = strings.Replace(name, "--", ".", -1)
+	return toCamelCase(name)
+}
+
+// isFlagValue reports whether arg can be the value of the flag before it.
+// Arguments starting with a dash are taken to be flags, except for a lone dash,
+// which commonly refers to stdin, and negative numbers.
+func isFlagValue(arg string) bool {
+	if arg == flagTerminator {
+		return false
+	}
+	return !strings.HasPrefix(arg, "-") || arg == "-" || isNumber(arg)
+}
+
+// isNumber reports whether arg is a decimal number such as `-3`, `-0.5`, or
+// `-1.5e3`. Other forms accepted by strconv, such as `-Inf` or hexadecimal
+// numbers, are not treated as numbers.
+func isNumber(arg string) bool {
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(trimSign(arg)), "e")
+	if hasExponent {
+		if !isDigits(trimSign(exponent)) {
+			return false
+		}
+	}
+	integer, fraction, _ := strings.Cut(mantissa, ".")
+	return (integer != "" || fraction != "") && (integer == "" || isDigits(integer)) && (fraction == "" || isDigits(fraction))
+}
+
+func trimSign(s string) string {
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return s[1:]
+	}
+	return s
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }) == -1
+}
+
+// flagTarget describes the paths of the struct configuration will be read
+// into, so the flag parser can tell boolean flags, which take no value, apart
+// from flags followed by their value. The zero value knows of no paths. known
+// is set when a target was given, in which case a group of short flags may
+// only contain flags of the target.
+type flagTarget struct {
+	paths map[string]reflect.Kind
+	known bool
+}
+
+// newFlagTarget collects the paths of target, which should be a pointer to a
+// struct such as the one later passed to GetAll, along with the paths of each
+// command's target scoped under the command's path. A nil target knows of no
+// paths.
+func newFlagTarget(target any, commands []*Command) flagTarget {
+	flagTarget := flagTarget{paths: map[string]reflect.Kind{}, known: target != nil}
+	if target != nil {
+		flagTarget.collectPaths("", reflect.TypeOf(target), map[reflect.Type]bool{})
+	}
+	flagTarget.collectCommandPaths(nil, commands)
+	return flagTarget
+}
+
+func (t flagTarget) collectCommandPaths(parentNames []string, commands []*Command) {
+	for _, command := range commands {
+		commandNames := append(append([]string{}, parentNames...), command.Name)
+		if command.Target != nil {
+			t.collectPaths(commandPath(commandNames), reflect.TypeOf(command.Target), map[reflect.Type]bool{})
+		}
+		t.collectCommandPaths(commandNames, command.Commands)
+	}
+}
+
+func (t flagTarget) collectPaths(currentPath string, typ reflect.Type, seenTypes map[reflect.Type]bool) {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		t.collectPaths(currentPath, typ.Elem(), seenTypes)
+
+	case reflect.Struct:
+		// Recursive types would otherwise be walked forever.
+		if seenTypes[typ] {
+			return
+		}
+		seenTypes[typ] = true
+		defer delete(seenTypes, typ)
+
+		for i := 0; i < typ.NumField(); i += 1 {
+			structField := typ.Field(i)
+			if !structField.IsExported() {
+				continue
+			}
+
+			tag, _ := parseFieldTag(structField)
+			fieldTag := tag.name
+			if structField.Anonymous && structField.Type.Kind() == reflect.Struct && fieldTag == "" {
+				t.collectPaths(currentPath, structField.Type, seenTypes)
+				continue
+			}
+			if fieldTag == "" {
+				fieldTag = calDefaultFieldTag(structField.Name)
+			}
+			fieldPath := toCamelCase(fieldTag)
+			if currentPath != "" {
+				fieldPath = currentPath + "." + fieldPath
+			}
+			t.collectPaths(fieldPath, structField.Type, seenTypes)
+		}
+
+	case reflect.Slice:
+		// Slices of structs are indexed, so their paths can't be known up
+		// front. Slices of other types are set by repeating the flag.
+		if typ.Elem().Kind() != reflect.Struct {
+			t.collectPaths(currentPath, typ.Elem(), seenTypes)
+		}
+
+	default:
+		if currentPath != "" {
+			t.paths[currentPath] = typ.Kind()
+		}
+	}
+}
+
+func (t flagTarget) has(path string) bool {
+	_, ok := t.paths[path]
+	return ok
+}
+
+func (t flagTarget) isBool(path string) bool {
+	return t.paths[path] == reflect.Bool
+}
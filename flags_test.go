@@ -0,0 +1,390 @@
+package orale_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestFlags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should set valueless flags to true", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.LoadFromValues([]string{"--verbose", "--port=80", "--debug"}, "", []string{}, "", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.FlagValues()["verbose"][0] != "true" {
+			t.Fatalf("expected verbose to be true, got %v", conf.FlagValues()["verbose"])
+		}
+		if conf.FlagValues()["port"][0] != "80" {
+			t.Fatalf("expected port to be 80, got %v", conf.FlagValues()["port"])
+		}
+		if conf.FlagValues()["debug"][0] != "true" {
+			t.Fatalf("expected trailing debug to be true, got %v", conf.FlagValues()["debug"])
+		}
+	})
+
+	t.Run("should take negative numbers and a lone dash as values", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.LoadFromValues([]string{"--offset", "-5", "--config-file", "-", "--name", "--other"}, "", []string{}, "", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.FlagValues()["offset"][0] != "-5" {
+			t.Fatalf("expected offset to be -5, got %v", conf.FlagValues()["offset"])
+		}
+		if conf.FlagValues()["configFile"][0] != "-" {
+			t.Fatalf("expected configFile to be -, got %v", conf.FlagValues()["configFile"])
+		}
+		if conf.FlagValues()["name"][0] != "true" {
+			t.Fatalf("expected name to be true, got %v", conf.FlagValues()["name"])
+		}
+	})
+
+	t.Run("should set flags prefixed with no- to false", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Verbose bool `config:"verbose"`
+			Color   bool `config:"color"`
+			NoCache bool `config:"noCache"`
+		}
+
+		testConf := TestConfig{Verbose: true, Color: true}
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--no-verbose", "--no-color", "--no-cache"}),
+			orale.WithEnviron([]string{}),
+			orale.WithWorkingDir(t.TempDir()),
+			orale.WithTarget(&testConf),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf.MustGetAll(&testConf)
+
+		if testConf.Verbose {
+			t.Fatal("expected Verbose to be false")
+		}
+		if testConf.Color {
+			t.Fatal("expected Color to be false")
+		}
+		if !testConf.NoCache {
+			t.Fatal("expected NoCache to be true")
+		}
+	})
+
+	t.Run("should only negate flags without a target when no value follows", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.LoadFromValues([]string{"--no-reply-address", "a@b", "--no-color", "--no-cache"}, "", []string{}, "", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.FlagValues()["noReplyAddress"][0] != "a@b" {
+			t.Fatalf("expected noReplyAddress to be a@b, got %v", conf.FlagValues())
+		}
+		if _, ok := conf.FlagValues()["replyAddress"]; ok {
+			t.Fatalf("expected replyAddress not to be set, got %v", conf.FlagValues())
+		}
+		if conf.FlagValues()["color"][0] != "false" || conf.FlagValues()["cache"][0] != "false" {
+			t.Fatalf("expected color and cache to be false, got %v", conf.FlagValues())
+		}
+		if len(conf.Args) != 0 {
+			t.Fatalf("expected no positional arguments, got %v", conf.Args)
+		}
+	})
+
+	t.Run("should stop parsing flags at --", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.LoadFromValues([]string{"--a=1", "--", "--b=2"}, "", []string{}, "", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.FlagValues()) != 1 {
+			t.Fatalf("expected 1 flag value, got %v", conf.FlagValues())
+		}
+	})
+
+	t.Run("should not give boolean flags of the target the following argument", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Verbose bool   `config:"verbose"`
+			Name    string `config:"name"`
+			Server  struct {
+				Tls bool `config:"tls"`
+			} `config:"server"`
+		}
+
+		testConf := TestConfig{}
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--verbose", "./data", "--name", "app", "--server--tls", "serve"}),
+			orale.WithEnviron([]string{}),
+			orale.WithWorkingDir(t.TempDir()),
+			orale.WithTarget(&testConf),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf.MustGetAll(&testConf)
+
+		if !testConf.Verbose {
+			t.Fatal("expected Verbose to be true")
+		}
+		if testConf.Name != "app" {
+			t.Fatalf("expected Name to be app, got %s", testConf.Name)
+		}
+		if !testConf.Server.Tls {
+			t.Fatal("expected Server.Tls to be true")
+		}
+	})
+
+	t.Run("should parse combined and attached short flags", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.LoadFromValues([]string{"-vvv", "-abc", "-p8080", "-o./out", "-xn", "5"}, "", []string{}, "", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.FlagValues()["v"]) != 3 {
+			t.Fatalf("expected v to be given 3 times, got %v", conf.FlagValues()["v"])
+		}
+		for _, key := range []string{"a", "b", "c", "x"} {
+			if conf.FlagValues()[key][0] != "true" {
+				t.Fatalf("expected %s to be true, got %v", key, conf.FlagValues()[key])
+			}
+		}
+		if conf.FlagValues()["p"][0] != "8080" {
+			t.Fatalf("expected p to be 8080, got %v", conf.FlagValues()["p"])
+		}
+		if conf.FlagValues()["o"][0] != "./out" {
+			t.Fatalf("expected o to be ./out, got %v", conf.FlagValues()["o"])
+		}
+		if conf.FlagValues()["n"][0] != "5" {
+			t.Fatalf("expected n to be 5, got %v", conf.FlagValues()["n"])
+		}
+	})
+
+	t.Run("should use the target to split short flags from attached values", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Verbose bool   `config:"v"`
+			Output  string `config:"o"`
+		}
+
+		testConf := TestConfig{}
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"-vofile"}),
+			orale.WithEnviron([]string{}),
+			orale.WithWorkingDir(t.TempDir()),
+			orale.WithTarget(&testConf),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf.MustGetAll(&testConf)
+
+		if !testConf.Verbose {
+			t.Fatal("expected Verbose to be true")
+		}
+		if testConf.Output != "file" {
+			t.Fatalf("expected Output to be file, got %s", testConf.Output)
+		}
+	})
+
+	t.Run("should parse single dash long flags when enabled", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"-verbose", "-port=80", "-name", "app"}),
+			orale.WithEnviron([]string{}),
+			orale.WithWorkingDir(t.TempDir()),
+			orale.WithSingleDashLongFlags(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.FlagValues()["verbose"][0] != "true" {
+			t.Fatalf("expected verbose to be true, got %v", conf.FlagValues()["verbose"])
+		}
+		if conf.FlagValues()["port"][0] != "80" {
+			t.Fatalf("expected port to be 80, got %v", conf.FlagValues()["port"])
+		}
+		if conf.FlagValues()["name"][0] != "app" {
+			t.Fatalf("expected name to be app, got %v", conf.FlagValues()["name"])
+		}
+	})
+
+	t.Run("should unquote quoted values", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.LoadFromValues([]string{`--a="hello \"world\""`, `--b='it is'`, `-c"x y"`, `--d=plain"`}, "", []string{}, "", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.FlagValues()["a"][0] != `hello "world"` {
+			t.Fatalf("expected a to be unquoted, got %v", conf.FlagValues()["a"])
+		}
+		if conf.FlagValues()["b"][0] != "it is" {
+			t.Fatalf("expected b to be unquoted, got %v", conf.FlagValues()["b"])
+		}
+		if conf.FlagValues()["c"][0] != "x y" {
+			t.Fatalf("expected c to be unquoted, got %v", conf.FlagValues()["c"])
+		}
+		if conf.FlagValues()["d"][0] != `plain"` {
+			t.Fatalf("expected d to be left alone, got %v", conf.FlagValues()["d"])
+		}
+	})
+
+	t.Run("should treat empty arguments, a lone dash, and negative numbers as positional", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.LoadFromValues([]string{"", "-", "-5", "-1.5e3"}, "", []string{}, "", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.FlagValues()) != 0 {
+			t.Fatalf("expected no flag values, got %v", conf.FlagValues())
+		}
+	})
+
+	t.Run("should only treat decimal numbers as negative numbers", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.LoadFromValues([]string{"-n", "-Inf", "-0x10"}, "", []string{}, "", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.FlagValues()["n"][0] != "true" {
+			t.Fatalf("expected n to be true, got %v", conf.FlagValues()["n"])
+		}
+		if len(conf.Args) != 0 {
+			t.Fatalf("expected no positional arguments, got %v", conf.Args)
+		}
+	})
+
+	t.Run("should reject single dash long flags with values without a target", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := orale.LoadFromValues([]string{"-port=80"}, "", []string{}, "", []string{})
+		var flagErr *orale.FlagError
+		if !errors.As(err, &flagErr) || !errors.Is(err, orale.ErrInvalidFlagName) {
+			t.Fatalf("expected ErrInvalidFlagName, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "WithSingleDashLongFlags") {
+			t.Fatalf("expected the error to suggest WithSingleDashLongFlags, got %v", err)
+		}
+
+		conf, err := orale.LoadFromValues([]string{"-port=80", "-p=81"}, "", []string{}, "", []string{}, orale.WithSingleDashLongFlags())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if conf.FlagValues()["port"][0] != "80" || conf.FlagValues()["p"][0] != "81" {
+			t.Fatalf("expected port to be 80 and p to be 81, got %v", conf.FlagValues())
+		}
+	})
+
+	t.Run("should return flag errors rather than panicking", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Verbose bool `config:"verbose"`
+			Port    int  `config:"port"`
+		}
+
+		cases := []struct {
+			args  []string
+			index int
+			err   error
+		}{
+			{[]string{"--=value"}, 0, orale.ErrInvalidFlagName},
+			{[]string{"ok", "---verbose"}, 1, orale.ErrInvalidFlagName},
+			{[]string{"--a-"}, 0, orale.ErrInvalidFlagName},
+			{[]string{"-%a"}, 0, orale.ErrInvalidFlagName},
+			{[]string{"-name=foo"}, 0, orale.ErrInvalidFlagName},
+			{[]string{"--name=\"unterminated"}, 0, orale.ErrUnterminatedQuote},
+			{[]string{"--port"}, 0, orale.ErrMissingFlagValue},
+			{[]string{"--port", "--verbose"}, 0, orale.ErrMissingFlagValue},
+			{[]string{"--verbose=maybe"}, 0, orale.ErrInvalidFlagValue},
+		}
+
+		for _, c := range cases {
+			_, err := orale.Load("app",
+				orale.WithArgs(c.args),
+				orale.WithEnviron([]string{}),
+				orale.WithWorkingDir(t.TempDir()),
+				orale.WithTarget(&TestConfig{}),
+			)
+
+			var flagErr *orale.FlagError
+			if !errors.As(err, &flagErr) {
+				t.Fatalf("expected a flag error for %q, got %v", c.args, err)
+			}
+			if !errors.Is(err, c.err) {
+				t.Fatalf("expected %v for %q, got %v", c.err, c.args, err)
+			}
+			if flagErr.Index != c.index {
+				t.Fatalf("expected index %d for %q, got %d", c.index, c.args, flagErr.Index)
+			}
+		}
+	})
+}
+
+func FuzzFlags(f *testing.F) {
+	f.Add("--verbose\n--port=80")
+	f.Add("-vvv\n-p8080\n--\n--ignored")
+	f.Add("--name\n\"quoted value\"\n--no-color")
+	f.Add("-\n\n-5\n--config-file=-")
+
+	type TestConfig struct {
+		Verbose bool     `config:"verbose"`
+		Port    int      `config:"port"`
+		Name    string   `config:"name"`
+		Tags    []string `config:"tags"`
+	}
+
+	f.Fuzz(func(t *testing.T, args string) {
+		for _, singleDashLongFlags := range []bool{false, true} {
+			opts := []orale.LoadOption{
+				orale.WithStdin(strings.NewReader("")),
+				orale.WithFS(fstest.MapFS{}),
+				orale.WithTarget(&TestConfig{}),
+			}
+			if singleDashLongFlags {
+				opts = append(opts, orale.WithSingleDashLongFlags())
+			}
+
+			conf, err := orale.LoadFromValues(strings.Split(args, "\n"), "APP", []string{}, ".", []string{"app"}, opts...)
+			if err != nil {
+				var flagErr *orale.FlagError
+				if errors.As(err, &flagErr) && flagErr.Error() == "" {
+					t.Fatal("expected flag errors to have a message")
+				}
+				continue
+			}
+			for key := range conf.FlagValues() {
+				if key == "" {
+					t.Fatalf("expected flag keys to be non empty for %q", args)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package orale
+
+import (
+	"fmt"
+	"strings"
+)
+
+// listValue is a value which can be read both as a single value and as a list.
+// It is produced by formats such as ini and properties where lists are written
+// as comma separated values.
+type listValue struct {
+	value string
+	items []any
+}
+
+// flatKeyValues collects values keyed by dotted paths for formats without a
+// native hierarchy. Keys ending in `[]` are assigned incrementing indexes.
+type flatKeyValues struct {
+	values      map[string]any
+	nextIndexes map[string]int
+}
+
+func newFlatKeyValues() *flatKeyValues {
+	return &flatKeyValues{
+		values:      map[string]any{},
+		nextIndexes: map[string]int{},
+	}
+}
+
+func (f *flatKeyValues) set(key string, value any) {
+	if strings.HasSuffix(key, "[]") {
+		keyPath := strings.TrimSuffix(key, "[]")
+		index := f.nextIndexes[keyPath]
+		f.nextIndexes[keyPath] = index + 1
+		key = fmt.Sprintf("%s[%d]", keyPath, index)
+	}
+	f.values[key] = value
+}
+
+// splitListValue splits an unquoted value on commas. If the value contains no
+// commas it is returned as is, otherwise a listValue is returned.
+func splitListValue(value string, unescape func(string) string) any {
+	rawItems := splitUnescaped(value, ',')
+	if len(rawItems) < 2 {
+		return unescape(value)
+	}
+	items := []any{}
+	for _, rawItem := range rawItems {
+		items = append(items, unescape(strings.TrimSpace(rawItem)))
+	}
+	return listValue{
+		value: unescape(value),
+		items: items,
+	}
+}
+
+// splitUnescaped splits value on separator, ignoring separators preceded by a
+// backslash.
+func splitUnescaped(value string, separator byte) []string {
+	parts := []string{}
+	start := 0
+	for i := 0; i < len(value); i += 1 {
+		if value[i] == '\\' {
+			i += 1
+			continue
+		}
+		if value[i] == separator {
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// decodeIni decodes ini files. Sections become path segments, so `port` in a
+// `[db.replica]` section becomes `db.replica.port`. Keys and values are
+// separated by `=` or `:`, and lines starting with `;` or `#` are comments.
+// Unquoted values containing commas may be read as lists, and keys ending in
+// `[]` are appended to a list.
+func decodeIni(fileBytes []byte) (map[string]any, error) {
+	flatValues := newFlatKeyValues()
+
+	section := ""
+	lines := strings.Split(strings.ReplaceAll(string(fileBytes), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			endIndex := strings.LastIndexByte(line, ']')
+			if endIndex == -1 {
+				return nil, fmt.Errorf("line %d: unterminated section header", i+1)
+			}
+			section = strings.TrimSpace(line[1:endIndex])
+			continue
+		}
+
+		splitIndex := strings.IndexAny(line, "=:")
+		if splitIndex == -1 {
+			return nil, fmt.Errorf("line %d: expected key = value", i+1)
+		}
+		key := strings.TrimSpace(line[:splitIndex])
+		rawValue := strings.TrimSpace(line[splitIndex+1:])
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", i+1)
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+
+		var value any
+		if len(rawValue) != 0 && (rawValue[0] == '"' || rawValue[0] == '\'') {
+			quotedValue, err := unquoteIniValue(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			value = quotedValue
+		} else {
+			for j := 0; j < len(rawValue); j += 1 {
+				if (rawValue[j] == ';' || rawValue[j] == '#') && j > 0 && (rawValue[j-1] == ' ' || rawValue[j-1] == '\t') {
+					rawValue = strings.TrimSpace(rawValue[:j])
+					break
+				}
+			}
+			value = splitListValue(rawValue, unescapeIniValue)
+		}
+
+		flatValues.set(key, value)
+	}
+
+	return flatValues.values, nil
+}
+
+func unquoteIniValue(rawValue string) (string, error) {
+	quote := rawValue[0]
+	value := strings.Builder{}
+	for i := 1; i < len(rawValue); i += 1 {
+		currentChar := rawValue[i]
+		if currentChar == quote {
+			remaining := strings.TrimSpace(rawValue[i+1:])
+			if remaining != "" && remaining[0] != ';' && remaining[0] != '#' {
+				return "", fmt.Errorf("unexpected characters after quoted value")
+			}
+			return value.String(), nil
+		}
+		if quote == '"' && currentChar == '\\' && i+1 < len(rawValue) {
+			i += 1
+			switch rawValue[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			default:
+				value.WriteByte(rawValue[i])
+			}
+			continue
+		}
+		value.WriteByte(currentChar)
+	}
+	return "", fmt.Errorf("unterminated quoted value")
+}
+
+func unescapeIniValue(value string) string {
+	return strings.ReplaceAll(value, "\\,", ",")
+}
@@ -0,0 +1,158 @@
+package orale
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// decodeJson decodes a json document, treating an empty document as an empty
+// object like the other formats do.
+func decodeJson(fileBytes []byte) (map[string]any, error) {
+	if len(bytes.TrimSpace(fileBytes)) == 0 {
+		return map[string]any{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(fileBytes))
+	decoder.UseNumber()
+
+	var hierarchicalFileValues map[string]any
+	if err := decoder.Decode(&hierarchicalFileValues); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after top level object")
+	}
+	if hierarchicalFileValues == nil {
+		hierarchicalFileValues = map[string]any{}
+	}
+	return normalizeJsonValue(hierarchicalFileValues).(map[string]any), nil
+}
+
+func decodeJsonc(fileBytes []byte) (map[string]any, error) {
+	return decodeJson(stripJsonc(fileBytes))
+}
+
+// normalizeJsonValue converts json numbers into int64 when they are whole
+// numbers that fit, uint64 for larger whole numbers that fit, and float64
+// otherwise. This matches the types produced by the toml decoder. Whole numbers
+// too large for either are kept as their literal so no digits are lost.
+func normalizeJsonValue(value any) any {
+	switch val := value.(type) {
+	case map[string]any:
+		for key, subValue := range val {
+			val[key] = normalizeJsonValue(subValue)
+		}
+		return val
+	case []any:
+		for i, subValue := range val {
+			val[i] = normalizeJsonValue(subValue)
+		}
+		return val
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(val.String(), 10, 64); err == nil {
+			return u
+		}
+		if !strings.ContainsAny(val.String(), ".eE") {
+			return val.String()
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	default:
+		return value
+	}
+}
+
+// stripJsonc removes line and block comments, and trailing commas from json
+// with comments so it can be decoded as standard json. Comments are replaced
+// with whitespace so positions reported in decode errors stay accurate.
+func stripJsonc(fileBytes []byte) []byte {
+	strippedBytes := make([]byte, 0, len(fileBytes))
+
+	inString := false
+	for i := 0; i < len(fileBytes); i += 1 {
+		currentChar := fileBytes[i]
+		var nextChar byte
+		if i+1 < len(fileBytes) {
+			nextChar = fileBytes[i+1]
+		}
+
+		switch {
+		case inString:
+			strippedBytes = append(strippedBytes, currentChar)
+			if currentChar == '\\' && i+1 < len(fileBytes) {
+				strippedBytes = append(strippedBytes, nextChar)
+				i += 1
+			} else if currentChar == '"' {
+				inString = false
+			}
+		case currentChar == '"':
+			inString = true
+			strippedBytes = append(strippedBytes, currentChar)
+		case currentChar == '/' && nextChar == '/':
+			for i < len(fileBytes) && fileBytes[i] != '\n' {
+				strippedBytes = append(strippedBytes, ' ')
+				i += 1
+			}
+			if i < len(fileBytes) {
+				strippedBytes = append(strippedBytes, '\n')
+			}
+		case currentChar == '/' && nextChar == '*':
+			strippedBytes = append(strippedBytes, ' ', ' ')
+			i += 2
+			for i < len(fileBytes) && !(fileBytes[i] == '*' && i+1 < len(fileBytes) && fileBytes[i+1] == '/') {
+				if fileBytes[i] == '\n' {
+					strippedBytes = append(strippedBytes, '\n')
+				} else {
+					strippedBytes = append(strippedBytes, ' ')
+				}
+				i += 1
+			}
+			if i < len(fileBytes) {
+				strippedBytes = append(strippedBytes, ' ', ' ')
+				i += 1
+			}
+		default:
+			strippedBytes = append(strippedBytes, currentChar)
+		}
+	}
+
+	return stripTrailingCommas(strippedBytes)
+}
+
+func stripTrailingCommas(fileBytes []byte) []byte {
+	inString := false
+	for i := 0; i < len(fileBytes); i += 1 {
+		currentChar := fileBytes[i]
+		switch {
+		case inString:
+			if currentChar == '\\' {
+				i += 1
+			} else if currentChar == '"' {
+				inString = false
+			}
+		case currentChar == '"':
+			inString = true
+		case currentChar == ',':
+			j := i + 1
+			for j < len(fileBytes) && isJsonWhitespace(fileBytes[j]) {
+				j += 1
+			}
+			if j < len(fileBytes) && (fileBytes[j] == ']' || fileBytes[j] == '}') {
+				fileBytes[i] = ' '
+			}
+		}
+	}
+	return fileBytes
+}
+
+func isJsonWhitespace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r'
+}
@@ -0,0 +1,123 @@
+package orale
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// decodeProperties decodes Java properties files. Dotted keys are already
+// paths, so `db.replica.port` maps to the same path as the equivalent toml.
+// Keys and values are separated by `=`, `:`, or whitespace, lines starting with
+// `#` or `!` are comments, and lines ending in a backslash continue on the next
+// line. Unescaped commas make a value readable as a list, and keys ending in
+// `[]` are appended to a list.
+func decodeProperties(fileBytes []byte) (map[string]any, error) {
+	flatValues := newFlatKeyValues()
+
+	lines := strings.Split(strings.ReplaceAll(string(fileBytes), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i += 1 {
+		lineNumber := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		for hasPropertiesContinuation(line) && i+1 < len(lines) {
+			i += 1
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if hasPropertiesContinuation(line) {
+			line = line[:len(line)-1]
+		}
+
+		keyEnd := len(line)
+		for j := 0; j < len(line); j += 1 {
+			if line[j] == '\\' {
+				j += 1
+				continue
+			}
+			if line[j] == '=' || line[j] == ':' || line[j] == ' ' || line[j] == '\t' || line[j] == '\f' {
+				keyEnd = j
+				break
+			}
+		}
+
+		valueStart := keyEnd
+		for valueStart < len(line) && (line[valueStart] == ' ' || line[valueStart] == '\t' || line[valueStart] == '\f') {
+			valueStart += 1
+		}
+		if valueStart < len(line) && (line[valueStart] == '=' || line[valueStart] == ':') {
+			valueStart += 1
+			for valueStart < len(line) && (line[valueStart] == ' ' || line[valueStart] == '\t' || line[valueStart] == '\f') {
+				valueStart += 1
+			}
+		}
+
+		key, err := unescapeProperty(line[:keyEnd])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNumber)
+		}
+
+		var unescapeErr error
+		value := splitListValue(line[valueStart:], func(rawValue string) string {
+			value, err := unescapeProperty(rawValue)
+			if err != nil && unescapeErr == nil {
+				unescapeErr = err
+			}
+			return value
+		})
+		if unescapeErr != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, unescapeErr)
+		}
+
+		flatValues.set(key, value)
+	}
+
+	return flatValues.values, nil
+}
+
+func hasPropertiesContinuation(line string) bool {
+	backslashCount := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i -= 1 {
+		backslashCount += 1
+	}
+	return backslashCount%2 == 1
+}
+
+func unescapeProperty(rawValue string) (string, error) {
+	value := strings.Builder{}
+	for i := 0; i < len(rawValue); i += 1 {
+		if rawValue[i] != '\\' || i+1 >= len(rawValue) {
+			value.WriteByte(rawValue[i])
+			continue
+		}
+		i += 1
+		switch rawValue[i] {
+		case 't':
+			value.WriteByte('\t')
+		case 'n':
+			value.WriteByte('\n')
+		case 'r':
+			value.WriteByte('\r')
+		case 'f':
+			value.WriteByte('\f')
+		case 'u':
+			if i+4 >= len(rawValue) {
+				return "", fmt.Errorf("invalid unicode escape")
+			}
+			codePoint, err := strconv.ParseUint(rawValue[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape: %w", err)
+			}
+			value.WriteRune(rune(codePoint))
+			i += 4
+		default:
+			value.WriteByte(rawValue[i])
+		}
+	}
+	return value.String(), nil
+}
@@ -0,0 +1,132 @@
+package orale
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// fileFormat describes a configuration file format and how to decode it into
+// hierarchical values.
+type fileFormat struct {
+	name       string
+	extensions []string
+	decode     func(fileBytes []byte) (map[string]any, error)
+}
+
+var tomlFormat = &fileFormat{
+	name:       "toml",
+	extensions: []string{"toml"},
+	decode:     decodeToml,
+}
+
+var yamlFormat = &fileFormat{
+	name:       "yaml",
+	extensions: []string{"yaml", "yml"},
+	decode:     decodeYaml,
+}
+
+var jsonFormat = &fileFormat{
+	name:       "json",
+	extensions: []string{"json"},
+	decode:     decodeJson,
+}
+
+var jsoncFormat = &fileFormat{
+	name:       "jsonc",
+	extensions: []string{"jsonc"},
+	decode:     decodeJsonc,
+}
+
+var iniFormat = &fileFormat{
+	name:       "ini",
+	extensions: []string{"ini"},
+	decode:     decodeIni,
+}
+
+var propertiesFormat = &fileFormat{
+	name:       "properties",
+	extensions: []string{"properties"},
+	decode:     decodeProperties,
+}
+
+// fileFormats lists the supported formats. When more than one file with the
+// same name is found in a directory, files of formats listed first take
+// precedence.
+var fileFormats = []*fileFormat{tomlFormat, yamlFormat, jsonFormat, jsoncFormat, iniFormat, propertiesFormat}
+
+func fileFormatFromPath(filePath string) *fileFormat {
+	return fileFormatFromName(path.Ext(filePath))
+}
+
+// fileFormatFromName returns the format with the given name or extension, such
+// as `yaml` or `yml`.
+func fileFormatFromName(name string) *fileFormat {
+	name = strings.TrimPrefix(name, ".")
+	for _, format := range fileFormats {
+		if strings.EqualFold(name, format.name) {
+			return format
+		}
+		for _, formatExtension := range format.extensions {
+			if strings.EqualFold(name, formatExtension) {
+				return format
+			}
+		}
+	}
+	return nil
+}
+
+func fileFormatExtensions() []string {
+	extensions := []string{}
+	for _, format := range fileFormats {
+		extensions = append(extensions, format.extensions...)
+	}
+	return extensions
+}
+
+func decodeToml(fileBytes []byte) (map[string]any, error) {
+	var hierarchicalFileValues map[string]any
+	if _, err := toml.Decode(string(fileBytes), &hierarchicalFileValues); err != nil {
+		return nil, err
+	}
+	return hierarchicalFileValues, nil
+}
+
+func decodeYaml(fileBytes []byte) (map[string]any, error) {
+	var hierarchicalFileValues map[string]any
+	if err := yaml.Unmarshal(fileBytes, &hierarchicalFileValues); err != nil {
+		return nil, err
+	}
+	if hierarchicalFileValues == nil {
+		hierarchicalFileValues = map[string]any{}
+	}
+	return normalizeYamlValue(hierarchicalFileValues).(map[string]any), nil
+}
+
+// normalizeYamlValue converts mappings with non string keys into maps with
+// string keys so they can be flattened like any other mapping.
+func normalizeYamlValue(value any) any {
+	switch val := value.(type) {
+	case map[string]any:
+		for key, subValue := range val {
+			val[key] = normalizeYamlValue(subValue)
+		}
+		return val
+	case map[any]any:
+		normalizedValue := map[string]any{}
+		for key, subValue := range val {
+			normalizedValue[fmt.Sprint(key)] = normalizeYamlValue(subValue)
+		}
+		return normalizedValue
+	case []any:
+		for i, subValue := range val {
+			val[i] = normalizeYamlValue(subValue)
+		}
+		return val
+	default:
+		return value
+	}
+}
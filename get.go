@@ -49,6 +49,16 @@ import (
 // specified by the `config` tag. If the `config` tag is not specified, the
 // property name is converted to snake case. For example `ConnectionUri` becomes
 // `connection_uri` path.
+//
+// Fields can also be bound to positional arguments with the `arg=<index>` tag
+// option, such as `config:"dir,arg=0"`, and a slice field can collect the
+// remaining positional arguments with the `args` option. Positional arguments
+// take precedence over values for the field's path, and are required unless
+// tagged `optional`. Boolean flags given before positional arguments can only
+// be told apart from flags with values when Load is given the target with
+// WithTarget. If the target has positional fields, Get returns an error
+// when arguments are missing, left over, or can't be converted to the field's
+// type.
 func (l *Loader) Get(path string, target any) error {
 	targetRefVal := reflect.ValueOf(target)
 	if targetRefVal.Kind() != reflect.Ptr {
@@ -56,7 +66,10 @@ func (l *Loader) Get(path string, target any) error {
 	}
 	targetRefVal = targetRefVal.Elem()
 
-	return getFromLoader(l, path, targetRefVal, 0)
+	if err := getFromLoader(l, path, targetRefVal, 0); err != nil {
+		return err
+	}
+	return getArgs(l, targetRefVal)
 }
 
 // MustGet is the same as Get except it panics if an error occurs.
@@ -100,9 +113,14 @@ func getFromLoader(l *Loader, currentPath string, targetRefVal reflect.Value, in
 				continue
 			}
 
+			tag, err := parseFieldTag(structField)
+			if err != nil {
+				return err
+			}
+
 			// Handle anonymous struct fields (embedded structs)
 			if structField.Anonymous && field.Kind() == reflect.Struct {
-				fieldTag := structField.Tag.Get("config")
+				fieldTag := tag.name
 				var embeddedPath string
 				if fieldTag != "" {
 					if currentPath != "" {
@@ -121,7 +139,7 @@ func getFromLoader(l *Loader, currentPath string, targetRefVal reflect.Value, in
 				continue
 			}
 
-			fieldTag := structField.Tag.Get("config")
+			fieldTag := tag.name
 			if fieldTag == "" {
 				fieldTag = calDefaultFieldTag(structField.Name)
 			}
@@ -239,15 +257,9 @@ func resolveValue(l *Loader, targetPath string) ([]any, error) {
 	if targetPath == "" {
 		return nil, fmt.Errorf("target path cannot be empty")
 	}
-	if value, ok := l.FlagValues[targetPath]; ok {
-		return value, nil
-	} else if value, ok := l.EnvironmentValues[targetPath]; ok {
-		return value, nil
-	} else {
-		for _, file := range l.ConfigurationFiles {
-			if value, ok := file.Values[targetPath]; ok {
-				return value, nil
-			}
+	for _, source := range l.orderedSources() {
+		if value, ok := source.FlatValues()[targetPath]; ok {
+			return value, nil
 		}
 	}
 	return nil, nil
@@ -258,38 +270,16 @@ func resolvePathLen(l *Loader, targetPath string) (int, error) {
 		return 0, fmt.Errorf("target path cannot be empty")
 	}
 
-	flagPaths := map[string]bool{}
-	for flagPath := range l.FlagValues {
-		slicePath := getSlicePathFromSubjectAndTargetPaths(flagPath, targetPath)
-		if slicePath != "" {
-			flagPaths[slicePath] = true
-		}
-	}
-	if len(flagPaths) != 0 {
-		return len(flagPaths), nil
-	}
-
-	environmentPaths := map[string]bool{}
-	for environmentPath := range l.EnvironmentValues {
-		slicePath := getSlicePathFromSubjectAndTargetPaths(environmentPath, targetPath)
-		if slicePath != "" {
-			environmentPaths[slicePath] = true
-		}
-	}
-	if len(environmentPaths) != 0 {
-		return len(environmentPaths), nil
-	}
-
-	for _, file := range l.ConfigurationFiles {
-		filePaths := map[string]bool{}
-		for filePath := range file.Values {
-			slicePath := getSlicePathFromSubjectAndTargetPaths(filePath, targetPath)
+	for _, source := range l.orderedSources() {
+		sourcePaths := map[string]bool{}
+		for sourcePath := range source.FlatValues() {
+			slicePath := getSlicePathFromSubjectAndTargetPaths(sourcePath, targetPath)
 			if slicePath != "" {
-				filePaths[slicePath] = true
+				sourcePaths[slicePath] = true
 			}
 		}
-		if len(filePaths) != 0 {
-			return len(filePaths), nil
+		if len(sourcePaths) != 0 {
+			return len(sourcePaths), nil
 		}
 	}
 
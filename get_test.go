@@ -8,25 +8,25 @@ import (
 
 func newTestLoaderSingleValues() *orale.Loader {
 	return &orale.Loader{
-		FlagValues: map[string][]any{
-			"a": {"1"},
-			"b": {"2"},
-			"c": {"3"},
-			"d": {"4"},
-		},
-		EnvironmentValues: map[string][]any{
-			"b": {"5"},
-			"e": {"6"},
-		},
-		ConfigurationFiles: []*orale.File{
-			{
+		Sources: []orale.Source{
+			orale.NewMapSource(orale.FlagSourceName, orale.FlagPriority, map[string][]any{
+				"a": {"1"},
+				"b": {"2"},
+				"c": {"3"},
+				"d": {"4"},
+			}),
+			orale.NewMapSource(orale.EnvironmentSourceName, orale.EnvironmentPriority, map[string][]any{
+				"b": {"5"},
+				"e": {"6"},
+			}),
+			&orale.File{
 				Path: "path/to/other/file-2.toml",
 				Values: map[string][]any{
 					"d": {"9"},
 					"g": {"10"},
 				},
 			},
-			{
+			&orale.File{
 				Path: "path/to/file-1.toml",
 				Values: map[string][]any{
 					"c": {"7"},
@@ -41,17 +41,18 @@ func newTestLoaderSingleValues() *orale.Loader {
 
 func newTestLoaderMultiValues() *orale.Loader {
 	return &orale.Loader{
-		FlagValues: map[string][]any{
-			"a": {"1", "2"},
-			"b": {"3", "4"},
-			"c": {"5", "6"},
-			"d": {"7", "8"},
+		Sources: []orale.Source{
+			orale.NewMapSource(orale.FlagSourceName, orale.FlagPriority, map[string][]any{
+				"a": {"1", "2"},
+				"b": {"3", "4"},
+				"c": {"5", "6"},
+				"d": {"7", "8"},
+			}),
+			orale.NewMapSource(orale.EnvironmentSourceName, orale.EnvironmentPriority, map[string][]any{
+				"b": {"9", "10"},
+				"e": {"11", "12"},
+			}),
 		},
-		EnvironmentValues: map[string][]any{
-			"b": {"9", "10"},
-			"e": {"11", "12"},
-		},
-		ConfigurationFiles: []*orale.File{},
 	}
 }
 
@@ -100,7 +101,7 @@ func TestGet(t *testing.T) {
 		testStruct := TestConversionStruct{}
 
 		conf := &orale.Loader{
-			EnvironmentValues: map[string][]any{
+			Sources: []orale.Source{orale.NewMapSource(orale.EnvironmentSourceName, orale.EnvironmentPriority, map[string][]any{
 				// Int conversions
 				"stringToInt":    {"42"},
 				"floatToInt":     {42.7},
@@ -134,7 +135,7 @@ func TestGet(t *testing.T) {
 				"floatToString":     {3.14},
 				"boolTrueToString":  {true},
 				"boolFalseToString": {false},
-			},
+			})},
 		}
 		
 		if err := conf.Get("", &testStruct); err != nil {
@@ -354,10 +355,12 @@ func TestGet(t *testing.T) {
 		}
 
 		conf := &orale.Loader{
-			FlagValues: map[string][]any{
-				"a": {"1"},
-				"b": {"2"},
-				"d": {"4"},
+			Sources: []orale.Source{
+				orale.NewMapSource(orale.FlagSourceName, orale.FlagPriority, map[string][]any{
+					"a": {"1"},
+					"b": {"2"},
+					"d": {"4"},
+				}),
 			},
 		}
 		if err := conf.Get("", &testStruct); err != nil {
@@ -0,0 +1,94 @@
+package orale
+
+import (
+	"fmt"
+	"strings"
+)
+
+// includeKeys are the top level keys of a configuration file which list other
+// configuration files to include.
+var includeKeys = []string{"include", "extends"}
+
+// extractIncludePatterns removes include directives from the top level of a
+// configuration file's values and returns the paths or glob patterns they
+// contain. A directive may be a single string or a list of strings.
+func extractIncludePatterns(hierarchicalFileValues map[string]any) ([]string, error) {
+	includePatterns := []string{}
+	for _, includeKey := range includeKeys {
+		value, ok := hierarchicalFileValues[includeKey]
+		if !ok {
+			continue
+		}
+		delete(hierarchicalFileValues, includeKey)
+
+		var items []any
+		switch val := value.(type) {
+		case string:
+			items = []any{val}
+		case []any:
+			items = val
+		case listValue:
+			items = val.items
+		default:
+			return nil, fmt.Errorf("%s must be a string or a list of strings", includeKey)
+		}
+		for _, item := range items {
+			includePattern, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a string or a list of strings", includeKey)
+			}
+			includePatterns = append(includePatterns, includePattern)
+		}
+	}
+	return includePatterns, nil
+}
+
+// loadIncludes loads the files included by a configuration file. Paths are
+// relative to the including file and may be glob patterns. The including file
+// takes precedence over the files it includes, and files included later take
+// precedence over files included earlier, so the returned files are in reverse
+// order. Glob patterns never match the including file. Included files may
+// include further files, and including a file which is already being loaded is
+// an error.
+func loadIncludes(fileSystem fileSystem, environmentNames []string, configFilePath string, includePatterns []string, includeStack []string) ([]*File, error) {
+	includeStack = append(append([]string{}, includeStack...), configFilePath)
+	includingDir := fileSystem.dir(configFilePath)
+
+	includedFilePaths := []string{}
+	for _, includePattern := range includePatterns {
+		resolvedPattern := fileSystem.resolve(includingDir, includePattern)
+		if !strings.ContainsAny(includePattern, "*?[") {
+			includedFilePaths = append(includedFilePaths, resolvedPattern)
+			continue
+		}
+		matches, err := fileSystem.glob(resolvedPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %s in %s: %w", includePattern, configFilePath, err)
+		}
+		for _, match := range matches {
+			// A pattern such as `*.toml` may match the including file itself,
+			// which is skipped rather than treated as a cycle.
+			if match != configFilePath {
+				includedFilePaths = append(includedFilePaths, match)
+			}
+		}
+	}
+
+	includedFiles := []*File{}
+	for i := len(includedFilePaths) - 1; i >= 0; i -= 1 {
+		includedFilePath := includedFilePaths[i]
+		for _, stackPath := range includeStack {
+			if stackPath == includedFilePath {
+				return nil, fmt.Errorf("include cycle detected: %s", strings.Join(append(includeStack, includedFilePath), " -> "))
+			}
+		}
+
+		files, err := loadIncludedFile(fileSystem, environmentNames, includedFilePath, includeStack)
+		if err != nil {
+			return nil, fmt.Errorf("failed to include %s in %s: %w", includedFilePath, configFilePath, err)
+		}
+		includedFiles = append(includedFiles, files...)
+	}
+
+	return includedFiles, nil
+}
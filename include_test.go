@@ -0,0 +1,164 @@
+package orale_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestInclude(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should load included configuration files relative to the including file", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"services/api/app.config.toml": {Data: []byte(`
+include = ["../../shared/logging.toml", "conf/*.yaml"]
+name = "api"
+
+[logging]
+level = "debug"
+`)},
+			"shared/logging.toml":         {Data: []byte("extends = \"base.json\"\n[logging]\nlevel = \"info\"\nformat = \"json\"")},
+			"shared/base.json":            {Data: []byte(`{"logging": {"format": "text", "output": "stdout"}, "name": "base"}`)},
+			"services/api/conf/10-a.yaml": {Data: []byte("tracing:\n  endpoint: a\n  sample: 1")},
+			"services/api/conf/20-b.yaml": {Data: []byte("tracing:\n  endpoint: b")},
+		}
+
+		type TestConfig struct {
+			Name    string `config:"name"`
+			Include string `config:"include"`
+			Logging struct {
+				Level  string `config:"level"`
+				Format string `config:"format"`
+				Output string `config:"output"`
+			} `config:"logging"`
+			Tracing struct {
+				Endpoint string `config:"endpoint"`
+				Sample   int    `config:"sample"`
+			} `config:"tracing"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("services/api"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedFiles := [][2]string{
+			{"services/api/app.config.toml", ""},
+			{"services/api/conf/20-b.yaml", "services/api/app.config.toml"},
+			{"services/api/conf/10-a.yaml", "services/api/app.config.toml"},
+			{"shared/logging.toml", "services/api/app.config.toml"},
+			{"shared/base.json", "shared/logging.toml"},
+		}
+		if len(conf.ConfigurationFiles()) != len(expectedFiles) {
+			t.Fatalf("expected %d configuration files, got %d", len(expectedFiles), len(conf.ConfigurationFiles()))
+		}
+		for i, expectedFile := range expectedFiles {
+			configFile := conf.ConfigurationFiles()[i]
+			if configFile.Path != expectedFile[0] || configFile.IncludedBy != expectedFile[1] {
+				t.Fatalf("expected configuration file %d to be %s included by %q, got %s included by %q", i, expectedFile[0], expectedFile[1], configFile.Path, configFile.IncludedBy)
+			}
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Name != "api" {
+			t.Fatalf("expected Name to be api, got %s", testConf.Name)
+		}
+		if testConf.Include != "" {
+			t.Fatalf("expected include directive not to be a config path, got %s", testConf.Include)
+		}
+		if testConf.Logging.Level != "debug" {
+			t.Fatalf("expected Logging.Level to be debug, got %s", testConf.Logging.Level)
+		}
+		if testConf.Logging.Format != "json" {
+			t.Fatalf("expected Logging.Format to be json, got %s", testConf.Logging.Format)
+		}
+		if testConf.Logging.Output != "stdout" {
+			t.Fatalf("expected Logging.Output to be stdout, got %s", testConf.Logging.Output)
+		}
+		if testConf.Tracing.Endpoint != "b" {
+			t.Fatalf("expected Tracing.Endpoint to be b, got %s", testConf.Tracing.Endpoint)
+		}
+		if testConf.Tracing.Sample != 1 {
+			t.Fatalf("expected Tracing.Sample to be 1, got %d", testConf.Tracing.Sample)
+		}
+	})
+
+	t.Run("should return an error when includes form a cycle", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"app.config.toml": {Data: []byte("include = \"a.toml\"")},
+			"a.toml":          {Data: []byte("include = \"b.toml\"")},
+			"b.toml":          {Data: []byte("include = \"a.toml\"")},
+		}
+
+		_, err := orale.Load("app", orale.WithArgs([]string{}), orale.WithEnviron([]string{}), orale.WithFS(fileSystem))
+		if err == nil || !strings.Contains(err.Error(), "include cycle") {
+			t.Fatalf("expected an include cycle error, got %v", err)
+		}
+	})
+
+	t.Run("should not match the including file with a glob pattern", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"app.config.toml": {Data: []byte("include = \"*.toml\"\na = \"app\"")},
+			"b.toml":          {Data: []byte("b = \"b\"")},
+		}
+
+		type TestConfig struct {
+			A string `config:"a"`
+			B string `config:"b"`
+		}
+
+		conf, err := orale.Load("app", orale.WithArgs([]string{}), orale.WithEnviron([]string{}), orale.WithFS(fileSystem))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "app" || testConf.B != "b" {
+			t.Fatalf("expected A to be app and B to be b, got %s and %s", testConf.A, testConf.B)
+		}
+	})
+
+	t.Run("should return an error when a file includes itself by name", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"app.config.toml": {Data: []byte("include = \"app.config.toml\"")},
+		}
+
+		_, err := orale.Load("app", orale.WithArgs([]string{}), orale.WithEnviron([]string{}), orale.WithFS(fileSystem))
+		if err == nil || !strings.Contains(err.Error(), "include cycle") {
+			t.Fatalf("expected an include cycle error, got %v", err)
+		}
+	})
+
+	t.Run("should return an error when an included file is missing", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"app.config.toml": {Data: []byte("include = \"missing.toml\"")},
+		}
+
+		_, err := orale.Load("app", orale.WithArgs([]string{}), orale.WithEnviron([]string{}), orale.WithFS(fileSystem))
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -2,6 +2,8 @@ package orale
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,25 +11,203 @@ import (
 )
 
 const configEnvironmentKey = "configEnvironment"
+const configFileKey = "configFile"
+const configFormatKey = "configFormat"
+const stdinConfigFilePath = "-"
+const systemConfigDir = "/etc"
 
 // Load loads configuration values from flags, environment variables, and
 // configuration files. Flags are taken from `os.Args[1:]`. Environment
-// variables are taken from `os.Environ()`. Configuration files are taken from
-// the working directory and all parent directories. The configuration file
-// name is the application name with the extension `.config.toml`. If the name
-// contain
-func Load(applicationName string) (*Loader, error) {
-	var workingDir string
-	if testWorkingDir != "" {
-		workingDir = testWorkingDir
-	} else {
-		dir, err := os.Getwd()
+// variables are taken from `os.Environ()`. Configuration files, and dotenv
+// files when enabled with WithDotenv, are taken from the working directory and
+// all parent directories. The configuration file
+// name is the application name converted to kebab case with the extension
+// `.config.toml`, and the environment variable prefix is the application name
+// converted to screaming snake case. Additional configuration files can be
+// given explicitly with the `--config-file` flag or the
+// `<PREFIX>__CONFIG_FILE` environment variable. Explicit files take precedence
+// over discovered files, and must exist. Any of these defaults can be overridden
+// with options such as WithArgs, WithEnviron, and WithWorkingDir.
+func Load(applicationName string, opts ...LoadOption) (*Loader, error) {
+	options := &loadOptions{
+		name:       toConfigName(applicationName),
+		args:       testArgs,
+		environ:    testEnvironment,
+		workingDir: testWorkingDir,
+		envPrefix:  toEnvPrefix(applicationName),
+		fileNames:  []string{toConfigName(applicationName)},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.args == nil {
+		options.args = os.Args[1:]
+	}
+	if options.environ == nil {
+		options.environ = os.Environ()
+	}
+	if options.workingDir == "" {
+		if options.fileSystem != nil {
+			options.workingDir = "."
+		} else {
+			dir, err := os.Getwd()
+			if err != nil {
+				return nil, err
+			}
+			options.workingDir = dir
+		}
+	}
+
+	return loadFromOptions(options)
+}
+
+// LoadFromValues works like Load, but allows the caller to specify configuration
+// such as flag and environment values, as well as which path to start searching
+// for configuration files and which configuration file names to look for.
+// Options given after these values are applied on top of them.
+func LoadFromValues(programArgs []string, envVarPrefix string, envVars []string, configSearchStartPath string, configFileNames []string, opts ...LoadOption) (*Loader, error) {
+	options := &loadOptions{
+		args:       programArgs,
+		environ:    envVars,
+		workingDir: configSearchStartPath,
+		envPrefix:  envVarPrefix,
+		fileNames:  configFileNames,
+	}
+	if len(configFileNames) != 0 {
+		options.name = configFileNames[0]
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return loadFromOptions(options)
+}
+
+func loadFromOptions(options *loadOptions) (*Loader, error) {
+	fileSystem := fileSystem{fsys: options.fileSystem}
+
+	priorities := resolvePriorities(options.precedence, options.priorities)
+
+	var responseFiles *responseFileReader
+	if options.responseFiles {
+		responseFiles = &responseFileReader{fileSystem: fileSystem, workingDir: options.workingDir}
+	}
+	flagTarget := newFlagTarget(options.target, options.commands)
+	flags, err := loadFlags(plainArguments(options.args), flagTarget, options.commands, options.singleDashLongFlags, responseFiles)
+	if err != nil {
+		return nil, err
+	}
+	flagValues := flags.values
+	environmentValues := loadEnvironment(options.envPrefix, options.environ)
+	environmentNames := extractEnvironmentNames(priorities, flagValues, environmentValues)
+	ancestorPaths, searchBoundary := ancestorSearchPaths(fileSystem, options)
+	dotenvValues := map[string][]any{}
+	if options.dotenv {
+		values, err := loadDotenvFiles(fileSystem, environmentNames, ancestorPaths, options.envPrefix, options.environ)
+		if err != nil {
+			return nil, err
+		}
+		dotenvValues = values
+	}
+	secretValues, err := loadSecretsDirs(fileSystem, options.secretsDirs)
+	if err != nil {
+		return nil, err
+	}
+	stdin := options.stdin
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	explicitConfigurationFiles, err := loadExplicitConfigurationFiles(fileSystem, environmentNames, options.workingDir, extractConfigFilePaths(priorities, flagValues, environmentValues), stdin, extractConfigFormat(priorities, flagValues, environmentValues))
+	if err != nil {
+		return nil, err
+	}
+	templates, err := newFileNameTemplates(options.fileNameTemplates, environmentNames, options.environ)
+	if err != nil {
+		return nil, err
+	}
+	if options.stopAtFirstMatch {
+		ancestorPaths, searchBoundary = stopAtFirstMatch(fileSystem, options, templates, ancestorPaths, searchBoundary)
+	}
+	searchLocations := configSearchLocations(fileSystem, options, ancestorPaths)
+	discoveredConfigurationFiles, err := loadConfigurationFiles(fileSystem, templates, searchLocations)
+	if err != nil {
+		return nil, err
+	}
+	configurationFiles := append(explicitConfigurationFiles, discoveredConfigurationFiles...)
+	defaultConfigurationFiles, err := loadDefaultConfigurationFiles(options.defaultFileSystems, templates, options.fileNames)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []Source{
+		NewMapSource(FlagSourceName, priorities[Flags], flagValues),
+		NewMapSource(EnvironmentSourceName, priorities[Environment], environmentValues),
+		NewMapSource(SecretsSourceName, priorities[Secrets], secretValues),
+		NewMapSource(DotenvSourceName, priorities[Dotenv], dotenvValues),
+	}
+	for _, configurationFile := range configurationFiles {
+		configurationFile.priority = priorities[Files]
+		sources = append(sources, configurationFile)
+	}
+	for _, defaultConfigurationFile := range defaultConfigurationFiles {
+		defaultConfigurationFile.priority = priorities[Defaults]
+		sources = append(sources, defaultConfigurationFile)
+	}
+	sources = append(sources, options.sources...)
+
+	searchPaths := []string{}
+	for _, searchLocation := range searchLocations {
+		searchPaths = append(searchPaths, searchLocation.dir)
+	}
+
+	return &Loader{
+		Sources:         sources,
+		SearchPaths:     searchPaths,
+		SearchBoundary:  searchBoundary,
+		FlagOrigins:     flags.origins,
+		Args:            flags.positionalArgs,
+		Command:         flags.commandNames,
+		HelpRequested:   flags.helpRequested,
+		CompletionShell: flags.completionShell,
+
+		name:       options.name,
+		envPrefix:  options.envPrefix,
+		commands:   options.commands,
+		flagTarget: flagTarget,
+	}, nil
+}
+
+// loadDefaultConfigurationFiles loads configuration files from the root of each
+// of the given file systems in order of precedence.
+func loadDefaultConfigurationFiles(defaultFileSystems []fs.FS, templates fileNameTemplates, configNames []string) ([]*File, error) {
+	defaultConfigurationFiles := []*File{}
+	for _, defaultFileSystem := range defaultFileSystems {
+		configurationFiles, err := loadConfigurationFiles(fileSystem{fsys: defaultFileSystem}, templates, []searchLocation{
+			{dir: ".", configNames: configNames},
+		})
 		if err != nil {
 			return nil, err
 		}
-		workingDir = dir
+		defaultConfigurationFiles = append(defaultConfigurationFiles, configurationFiles...)
 	}
+	return defaultConfigurationFiles, nil
+}
 
+// environMap converts environment variables in the format returned by
+// `os.Environ()` into a map. Later values for the same key override earlier
+// ones.
+func environMap(envVars []string) map[string]string {
+	environ := map[string]string{}
+	for _, envVar := range envVars {
+		if key, value, ok := strings.Cut(envVar, "="); ok {
+			environ[key] = value
+		}
+	}
+	return environ
+}
+
+func toEnvPrefix(applicationName string) string {
 	applicationNameRunes := []rune(applicationName)
 
 	envPrefixRunes := []rune{}
@@ -49,7 +229,11 @@ func Load(applicationName string) (*Loader, error) {
 			envPrefixRunes = append(envPrefixRunes, currentChar)
 		}
 	}
-	envPrefix := string(envPrefixRunes)
+	return string(envPrefixRunes)
+}
+
+func toConfigName(applicationName string) string {
+	applicationNameRunes := []rune(applicationName)
 
 	configNameRunes := []rune{}
 	for i := 0; i < len(applicationNameRunes); i += 1 {
@@ -70,102 +254,7 @@ func Load(applicationName string) (*Loader, error) {
 			}
 		}
 	}
-	configName := string(configNameRunes)
-
-	var args []string
-	if testArgs != nil {
-		args = testArgs
-	} else {
-		args = os.Args[1:]
-	}
-
-	var envVars []string
-	if testEnvironment != nil {
-		envVars = testEnvironment
-	} else {
-		envVars = os.Environ()
-	}
-
-	return LoadFromValues(
-		args,
-		envPrefix,
-		envVars,
-		workingDir,
-		[]string{configName},
-	)
-}
-
-// LoadFromValues works like Load, but allows the caller to specify configuration
-// such as flag and environment values, as well as which path to start searching
-// for configuration files and which configuration file names to look for.
-func LoadFromValues(programArgs []string, envVarPrefix string, envVars []string, configSearchStartPath string, configFileNames []string) (*Loader, error) {
-	flagValues := loadFlags(programArgs)
-	environmentValues := loadEnvironment(envVarPrefix, envVars)
-	environmentName := extractEnvironmentName(flagValues, environmentValues)
-	configurationFiles, err := loadConfigurationFiles(environmentName, configSearchStartPath, configFileNames)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Loader{
-		FlagValues:         flagValues,
-		EnvironmentValues:  environmentValues,
-		ConfigurationFiles: configurationFiles,
-	}, nil
-}
-
-// NOTE: programArgs should not include the program name - os.Args[1:]
-// would be appropriate
-func loadFlags(programArgs []string) map[string][]any {
-	flagValues := map[string][]any{}
-
-	previousFlag := ""
-	for _, arg := range programArgs {
-		if previousFlag != "" {
-			arg = previousFlag + "=" + arg
-			previousFlag = ""
-		}
-
-		isShortFlag := arg[0] == '-' && arg[1] != '-'
-		isFlag := !isShortFlag && arg[0:2] == "--"
-
-		var startIndex int
-		switch {
-		case isShortFlag:
-			startIndex = 1
-		case isFlag:
-			startIndex = 2
-		default:
-			continue
-		}
-
-		splitIndex := -1
-		for i := startIndex; i < len(arg); i += 1 {
-			if arg[i] == '=' {
-				splitIndex = i
-				break
-			}
-		}
-		if splitIndex == -1 {
-			previousFlag = arg
-			continue
-		}
-
-		key := arg[startIndex:splitIndex]
-		value := arg[splitIndex+1:]
-
-		key = strings.ToLower(key)
-		key = strings.Replace(key, ".", "\\.", -1)
-		key = strings.Replace(key, "--", ".", -1)
-		key = toCamelCase(key)
-
-		if _, ok := flagValues[key]; !ok {
-			flagValues[key] = []any{}
-		}
-		flagValues[key] = append(flagValues[key], value)
-	}
-
-	return flagValues
+	return string(configNameRunes)
 }
 
 // NOTE: envVariables should be in the same format as the returned value from
@@ -187,14 +276,9 @@ func loadEnvironment(variablePrefix string, envVariables []string) map[string][]
 				continue
 			}
 
-			key := envVariable[len(variablePrefix):splitIndex]
+			key := environmentKeyToPath(envVariable[len(variablePrefix):splitIndex])
 			value := envVariable[splitIndex+1:]
 
-			key = strings.ToLower(key)
-			key = strings.Replace(key, ".", "\\.", -1)
-			key = strings.Replace(key, "__", ".", -1)
-			key = toCamelCase(key)
-
 			if _, ok := environmentValues[key]; !ok {
 				environmentValues[key] = []any{}
 			}
@@ -205,69 +289,237 @@ func loadEnvironment(variablePrefix string, envVariables []string) map[string][]
 	return environmentValues
 }
 
-func extractEnvironmentName(flagValues map[string][]any, environmentValues map[string][]any) string {
-	for key, values := range flagValues {
-		if key == configEnvironmentKey {
-			return values[0].(string)
+// environmentKeyToPath converts an environment variable name, without its
+// prefix, into a path. Double underscores separate path segments, and the
+// segments are converted to camel case.
+func environmentKeyToPath(key string) string {
+	key = strings.ToLower(key)
+	key = strings.Replace(key, ".", "\\.", -1)
+	key = strings.Replace(key, "__", ".", -1)
+	return toCamelCase(key)
+}
+
+// extractEnvironmentNames returns the configuration environments given with
+// `--config-environment` or `<PREFIX>__CONFIG_ENVIRONMENT`. Several
+// environments may be given separated by commas, or by repeating the flag.
+func extractEnvironmentNames(priorities map[SourceKind]int, flagValues map[string][]any, environmentValues map[string][]any) []string {
+	environmentNames := []string{}
+	values, _ := extractReservedValues(priorities, configEnvironmentKey, flagValues, environmentValues)
+	for _, value := range values {
+		for _, environmentName := range strings.Split(value, ",") {
+			environmentName = strings.TrimSpace(environmentName)
+			if environmentName != "" {
+				environmentNames = append(environmentNames, environmentName)
+			}
 		}
 	}
-	for key, values := range environmentValues {
-		if key == configEnvironmentKey {
-			return values[0].(string)
+	return environmentNames
+}
+
+// extractConfigFilePaths returns the paths of configuration files given
+// explicitly with `--config-file` or `<PREFIX>__CONFIG_FILE`. The flag may be
+// repeated, and each flag value is a single path. The environment variable may
+// contain a list of paths separated by the operating system's path list
+// separator. Only the source with the higher priority is used, so the
+// environment variable is ignored when the flag is given.
+func extractConfigFilePaths(priorities map[SourceKind]int, flagValues map[string][]any, environmentValues map[string][]any) []string {
+	values, kind := extractReservedValues(priorities, configFileKey, flagValues, environmentValues)
+	if kind == Flags {
+		return values
+	}
+	configFilePaths := []string{}
+	for _, value := range values {
+		for _, configFilePath := range filepath.SplitList(value) {
+			if configFilePath != "" {
+				configFilePaths = append(configFilePaths, configFilePath)
+			}
 		}
 	}
-	return ""
+	return configFilePaths
 }
 
-func loadConfigurationFiles(environmentName string, startPath string, configNames []string) ([]*File, error) {
-	currentPathChunks := strings.Split(startPath, string(filepath.Separator))
+// extractConfigFormat returns the format of a configuration file read from
+// stdin given with `--config-format` or `<PREFIX>__CONFIG_FORMAT`. Defaults to
+// toml.
+func extractConfigFormat(priorities map[SourceKind]int, flagValues map[string][]any, environmentValues map[string][]any) string {
+	values, _ := extractReservedValues(priorities, configFormatKey, flagValues, environmentValues)
+	if len(values) == 0 {
+		return tomlFormat.name
+	}
+	return values[len(values)-1]
+}
 
-	configFiles := []*File{}
-	for i := len(currentPathChunks); i > 0; i -= 1 {
-		isAbsPath := currentPathChunks[0] == ""
-		currentPath := filepath.Join(currentPathChunks[:i]...)
-		if isAbsPath {
-			currentPath = string(filepath.Separator) + currentPath
+// extractReservedValues returns the values of a reserved key such as
+// configEnvironment from whichever of the flags or environment variables has
+// the highest priority and contains the key, along with the kind of source
+// they came from.
+func extractReservedValues(priorities map[SourceKind]int, key string, flagValues map[string][]any, environmentValues map[string][]any) ([]string, SourceKind) {
+	orderedKinds := []SourceKind{Flags, Environment}
+	if priorities[Environment] > priorities[Flags] {
+		orderedKinds = []SourceKind{Environment, Flags}
+	}
+	valuesByKind := map[SourceKind]map[string][]any{Flags: flagValues, Environment: environmentValues}
+	for _, kind := range orderedKinds {
+		if value, ok := valuesByKind[kind][key]; ok && len(value) != 0 {
+			stringValues := []string{}
+			for _, v := range value {
+				stringValue, _ := intoString(v)
+				stringValues = append(stringValues, stringValue)
+			}
+			return stringValues, kind
 		}
+	}
+	return nil, Flags
+}
 
-		for _, configName := range configNames {
-			fullConfigName := ""
-			if environmentName == "" {
-				fullConfigName = fmt.Sprintf("%s.config.toml", configName)
-			} else {
-				fullConfigName = fmt.Sprintf("%s.%s.config.toml", configName, environmentName)
+// loadExplicitConfigurationFiles loads configuration files given by path.
+// Unlike discovered files, a missing file is an error. Files given later take
+// precedence over files given earlier, so the returned files are in reverse
+// order. A path of `-` reads a file in the given format from stdin, which may
+// only be done once. Includes within it are resolved relative to the working
+// directory.
+func loadExplicitConfigurationFiles(fileSystem fileSystem, environmentNames []string, workingDir string, configFilePaths []string, stdin io.Reader, stdinFormat string) ([]*File, error) {
+	stdinRead := false
+	configFiles := []*File{}
+	for i := len(configFilePaths) - 1; i >= 0; i -= 1 {
+		var files []*File
+		var err error
+		if configFilePaths[i] == stdinConfigFilePath {
+			if stdinRead {
+				return nil, fmt.Errorf("configuration file %s may only be given once", stdinConfigFilePath)
 			}
+			stdinRead = true
+			files, err = loadStdinFile(fileSystem, environmentNames, workingDir, stdin, stdinFormat)
+		} else {
+			files, err = loadFile(fileSystem, environmentNames, fileSystem.resolve(workingDir, configFilePaths[i]))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration file %s: %w", configFilePaths[i], err)
+		}
+		configFiles = append(configFiles, files...)
+	}
+	return configFiles, nil
+}
 
-			maybeConfigFilePath := filepath.Join(currentPath, fullConfigName)
-			maybeConfigFile, err := maybeLoadFile(maybeConfigFilePath)
-			if err != nil {
-				return nil, err
-			}
-			if maybeConfigFile == nil {
-				continue
-			}
+// loadStdinFile loads a configuration file in the given format from stdin. The
+// file's path is `-`.
+func loadStdinFile(fileSystem fileSystem, environmentNames []string, workingDir string, stdin io.Reader, format string) ([]*File, error) {
+	fileFormat := fileFormatFromName(format)
+	if fileFormat == nil {
+		return nil, fmt.Errorf("unsupported configuration file format: %s", format)
+	}
+	fileBytes, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, err
+	}
+	files, err := loadFormattedFileBytes(fileSystem, environmentNames, fileSystem.join(workingDir, stdinConfigFilePath), fileFormat, fileBytes, nil)
+	if err != nil {
+		return nil, err
+	}
+	files[0].Path = stdinConfigFilePath
+	return files, nil
+}
+
+// loadConfigurationFiles searches the given locations for configuration files.
+// At each directory a file is looked for with each supported extension for
+// every name produced by the file name templates. With the default templates
+// the base file `<name>.config.<ext>` is loaded along with a
+// `<name>.<environment>.config.<ext>` file for each environment, and `.local`
+// variants of both. Environment files take precedence over the base file,
+// later environments take precedence over earlier ones, and local files take
+// precedence over the rest. Each of these files may be accompanied by a
+// drop-in directory such as `<name>.config.d/`, whose files take precedence
+// over the file they accompany, and are applied in lexical order so later files
+// take precedence over earlier ones. Files in earlier locations take
+// precedence over files in later ones.
+func loadConfigurationFiles(fileSystem fileSystem, templates fileNameTemplates, searchLocations []searchLocation) ([]*File, error) {
+	environmentNames := templates.environmentNames
+
+	configFiles := []*File{}
+	for _, searchLocation := range searchLocations {
+		for _, configName := range searchLocation.configNames {
+			for _, configLayerName := range templates.layerNames(configName) {
+				dropInFilePaths, err := dropInConfigFilePaths(fileSystem, fileSystem.join(searchLocation.dir, configLayerName+".d"))
+				if err != nil {
+					return nil, err
+				}
+				for i := len(dropInFilePaths) - 1; i >= 0; i -= 1 {
+					dropInFiles, err := loadFile(fileSystem, environmentNames, dropInFilePaths[i])
+					if err != nil {
+						return nil, err
+					}
+					configFiles = append(configFiles, dropInFiles...)
+				}
+
+				for _, extension := range fileFormatExtensions() {
+					maybeConfigFilePath := fileSystem.join(searchLocation.dir, configLayerName+"."+extension)
+					maybeConfigFiles, err := maybeLoadFile(fileSystem, environmentNames, maybeConfigFilePath)
+					if err != nil {
+						return nil, err
+					}
 
-			configFiles = append(configFiles, maybeConfigFile)
+					configFiles = append(configFiles, maybeConfigFiles...)
+				}
+			}
 		}
 	}
 
 	return configFiles, nil
 }
 
+// dropInConfigFilePaths returns the paths of the configuration files within a
+// drop-in directory in lexical order. Hidden files, directories, and files of
+// unsupported formats are ignored. A missing directory yields no paths.
+func dropInConfigFilePaths(fileSystem fileSystem, dropInDir string) ([]string, error) {
+	dropInDirInfo, err := fileSystem.stat(dropInDir)
+	if err != nil {
+		if isMissingFileErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !dropInDirInfo.IsDir() {
+		return nil, nil
+	}
+
+	entries, err := fileSystem.readDir(dropInDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dropInFilePaths := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || fileFormatFromPath(entry.Name()) == nil {
+			continue
+		}
+		dropInFilePaths = append(dropInFilePaths, fileSystem.join(dropInDir, entry.Name()))
+	}
+	return dropInFilePaths, nil
+}
+
 var testWorkingDir string
 
+// Test_SetWorkingDir sets the working directory used by Load.
+//
+// Deprecated: Use the WithWorkingDir option instead.
 func Test_SetWorkingDir(dir string) {
 	testWorkingDir = dir
 }
 
 var testArgs []string
 
+// Test_SetArgs sets the program arguments used by Load.
+//
+// Deprecated: Use the WithArgs option instead.
 func Test_SetArgs(args []string) {
 	testArgs = args
 }
 
 var testEnvironment []string
 
+// Test_SetEnvironment sets the environment variables used by Load.
+//
+// Deprecated: Use the WithEnviron option instead.
 func Test_SetEnvironment(env []string) {
 	testEnvironment = env
 }
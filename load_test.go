@@ -1,34 +1,34 @@
 package orale_test
 
 import (
-	"os"
+	"embed"
+	"io/fs"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/RobertWHurst/orale"
 )
 
-func TestLoad(t *testing.T) {
-	cwd, err := os.Getwd()
-	if err != nil {
-		panic(err)
-	}
-	defer os.Chdir(cwd)
+//go:embed test-assets/defaults-dir
+var embeddedDefaults embed.FS
 
-	os.Chdir(testAssetsPath)
+func TestLoad(t *testing.T) {
+	t.Parallel()
 
 	t.Run("should load flags, environment variables, and configuration files, then correctly assign them to a struct", func(t *testing.T) {
-		orale.Test_SetArgs([]string{
+		t.Parallel()
+
+		args := []string{
 			"--oh--simple-as=Do",
 			"--oh--simple-as=Re",
 			"--oh--simple-as=Mi",
-		})
-		defer orale.Test_SetArgs([]string{})
+		}
 
-		orale.Test_SetEnvironment([]string{
+		environ := []string{
 			"TEST_APPLICATION__ABC_123_BABY_YOU_AND_ME_GIRL=true",
-		})
-		defer orale.Test_SetEnvironment([]string{})
+		}
 
 		type TestConfig struct {
 			A    string `config:"a"`
@@ -46,7 +46,7 @@ func TestLoad(t *testing.T) {
 			Abc123BabyYouAndMeGirl bool `config:"abc123BabyYouAndMeGirl"`
 		}
 
-		conf, err := orale.Load("testApplication")
+		conf, err := orale.Load("testApplication", orale.WithArgs(args), orale.WithEnviron(environ), orale.WithWorkingDir(testAssetsPath))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -105,18 +105,19 @@ func TestLoad(t *testing.T) {
 	})
 
 	t.Run("should load flags that are space delimited", func(t *testing.T) {
-		orale.Test_SetArgs([]string{
+		t.Parallel()
+
+		args := []string{
 			"--foo", "foo",
 			"--foo", "bar",
 			"--foo", "baz",
-		})
-		defer orale.Test_SetArgs([]string{})
+		}
 
 		type TestConfig struct {
 			Foo []string `config:"foo"`
 		}
 
-		conf, err := orale.Load("testApplication")
+		conf, err := orale.Load("testApplication", orale.WithArgs(args), orale.WithEnviron([]string{}), orale.WithWorkingDir(testAssetsPath))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -142,17 +143,18 @@ func TestLoad(t *testing.T) {
 	})
 
 	t.Run("should load configuration files for a specific environment when environment is set as a flag", func(t *testing.T) {
-		orale.Test_SetArgs([]string{
+		t.Parallel()
+
+		args := []string{
 			"--config-environment", "test",
-		})
-		defer orale.Test_SetArgs([]string{})
+		}
 
 		type TestConfig struct {
 			TestVal1 int `config:"testVal1"`
 			TestVal2 int `config:"testVal2"`
 		}
 
-		conf, err := orale.Load("testApplication")
+		conf, err := orale.Load("testApplication", orale.WithArgs(args), orale.WithEnviron([]string{}), orale.WithWorkingDir(testAssetsPath))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -169,17 +171,18 @@ func TestLoad(t *testing.T) {
 	})
 
 	t.Run("should load configuration files for a specific environment when environment is set as an environment variable", func(t *testing.T) {
-		orale.Test_SetEnvironment([]string{
+		t.Parallel()
+
+		environ := []string{
 			"TEST_APPLICATION__CONFIG_ENVIRONMENT=test",
-		})
-		defer orale.Test_SetEnvironment([]string{})
+		}
 
 		type TestConfig struct {
 			TestVal1 int `config:"testVal1"`
 			TestVal2 int `config:"testVal2"`
 		}
 
-		conf, err := orale.Load("testApplication")
+		conf, err := orale.Load("testApplication", orale.WithArgs([]string{}), orale.WithEnviron(environ), orale.WithWorkingDir(testAssetsPath))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -194,6 +197,745 @@ func TestLoad(t *testing.T) {
 			t.Fatalf("expected TestVal2 to be 20, got %d", testConf.TestVal2)
 		}
 	})
+
+	t.Run("should load configuration files from a given file system using the given file names and env prefix", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"project/other.config.toml":     {Data: []byte("a = \"project\"\nb = \"project\"")},
+			"other.config.toml":             {Data: []byte("b = \"root\"\nc = \"root\"")},
+			"project/sub/app.config.toml":   {Data: []byte("a = \"ignored\"")},
+			"project/sub/other.config.toml": {Data: []byte("d = \"sub\"")},
+		}
+
+		type TestConfig struct {
+			A string `config:"a"`
+			B string `config:"b"`
+			C string `config:"c"`
+			D string `config:"d"`
+			E string `config:"e"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{"CUSTOM__E=env", "APP__D=ignored"}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project/sub"),
+			orale.WithFileNames("other"),
+			orale.WithEnvPrefix("CUSTOM"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.ConfigurationFiles()) != 3 {
+			t.Fatalf("expected 3 configuration files, got %d", len(conf.ConfigurationFiles()))
+		}
+		if conf.ConfigurationFiles()[0].Path != "project/sub/other.config.toml" {
+			t.Fatalf("expected first configuration file to be project/sub/other.config.toml, got %s", conf.ConfigurationFiles()[0].Path)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "project" {
+			t.Fatalf("expected A to be project, got %s", testConf.A)
+		}
+		if testConf.B != "project" {
+			t.Fatalf("expected B to be project, got %s", testConf.B)
+		}
+		if testConf.C != "root" {
+			t.Fatalf("expected C to be root, got %s", testConf.C)
+		}
+		if testConf.D != "sub" {
+			t.Fatalf("expected D to be sub, got %s", testConf.D)
+		}
+		if testConf.E != "env" {
+			t.Fatalf("expected E to be env, got %s", testConf.E)
+		}
+	})
+
+	t.Run("should load yaml configuration files", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Server struct {
+				Host string `config:"host"`
+				Port int    `config:"port"`
+			} `config:"server"`
+			Channels []struct {
+				Name string `config:"name"`
+				Id   int    `config:"id"`
+			} `config:"channels"`
+			Tags []string `config:"tags"`
+		}
+
+		conf, err := orale.Load("yamlApp", orale.WithArgs([]string{}), orale.WithEnviron([]string{}), orale.WithWorkingDir(filepath.Join(testAssetsPath, "yaml-dir")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.ConfigurationFiles()[0].Values["channels[1].name"][0] != "News Item" {
+			t.Fatalf("expected channels[1].name to be News Item, got %v", conf.ConfigurationFiles()[0].Values["channels[1].name"])
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Server.Host != "localhost" {
+			t.Fatalf("expected Server.Host to be localhost, got %s", testConf.Server.Host)
+		}
+		if testConf.Server.Port != 8080 {
+			t.Fatalf("expected Server.Port to be 8080, got %d", testConf.Server.Port)
+		}
+		if len(testConf.Channels) != 2 {
+			t.Fatalf("expected Channels to have 2 values, got %d", len(testConf.Channels))
+		}
+		if testConf.Channels[1].Name != "News Item" || testConf.Channels[1].Id != 2 {
+			t.Fatalf("expected Channels[1] to be News Item 2, got %v", testConf.Channels[1])
+		}
+		if len(testConf.Tags) != 3 || testConf.Tags[2] != "c" {
+			t.Fatalf("expected Tags to be [a b c], got %v", testConf.Tags)
+		}
+	})
+
+	t.Run("should load yaml configuration files for a specific environment", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Server struct {
+				Host string `config:"host"`
+			} `config:"server"`
+		}
+
+		conf, err := orale.Load("yamlApp", orale.WithArgs([]string{"--config-environment=staging"}), orale.WithEnviron([]string{}), orale.WithWorkingDir(filepath.Join(testAssetsPath, "yaml-dir")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Server.Host != "staging.example.com" {
+			t.Fatalf("expected Server.Host to be staging.example.com, got %s", testConf.Server.Host)
+		}
+	})
+
+	t.Run("should load json and jsonc configuration files", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Url    string `config:"url"`
+			BigId  int64  `config:"bigId"`
+			Server struct {
+				Host  string  `config:"host"`
+				Port  int     `config:"port"`
+				Ratio float64 `config:"ratio"`
+			} `config:"server"`
+			Channels []struct {
+				Name string `config:"name"`
+				Id   int    `config:"id"`
+			} `config:"channels"`
+		}
+
+		conf, err := orale.Load("jsonApp", orale.WithArgs([]string{}), orale.WithEnviron([]string{}), orale.WithWorkingDir(filepath.Join(testAssetsPath, "json-dir")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.ConfigurationFiles()) != 2 {
+			t.Fatalf("expected 2 configuration files, got %d", len(conf.ConfigurationFiles()))
+		}
+		if conf.ConfigurationFiles()[0].Values["bigId"][0] != int64(9007199254740993) {
+			t.Fatalf("expected bigId to be int64(9007199254740993), got %#v", conf.ConfigurationFiles()[0].Values["bigId"][0])
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Url != "https://example.com/path" {
+			t.Fatalf("expected Url to be https://example.com/path, got %s", testConf.Url)
+		}
+		if testConf.BigId != 9007199254740993 {
+			t.Fatalf("expected BigId to be 9007199254740993, got %d", testConf.BigId)
+		}
+		if testConf.Server.Port != 8080 {
+			t.Fatalf("expected Server.Port to be 8080, got %d", testConf.Server.Port)
+		}
+		if testConf.Server.Ratio != 0.5 {
+			t.Fatalf("expected Server.Ratio to be 0.5, got %g", testConf.Server.Ratio)
+		}
+		if testConf.Server.Host != "example.com" {
+			t.Fatalf("expected Server.Host to be example.com, got %s", testConf.Server.Host)
+		}
+		if len(testConf.Channels) != 2 || testConf.Channels[1].Name != "News, Item" {
+			t.Fatalf("expected Channels[1].Name to be News, Item, got %v", testConf.Channels)
+		}
+	})
+
+	t.Run("should load ini and properties configuration files", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Name   string   `config:"name"`
+			Tags   []string `config:"tags"`
+			TagStr string   `config:"tags"`
+			Quoted []string `config:"quoted"`
+			Db     struct {
+				Host     string `config:"host"`
+				MaxConns int    `config:"maxConns"`
+				Replica  struct {
+					Host string `config:"host"`
+				} `config:"replica"`
+			} `config:"db"`
+			Channels []struct {
+				Name string `config:"name"`
+			} `config:"channels"`
+		}
+
+		for _, format := range []string{"ini", "properties"} {
+			conf, err := orale.Load(format+"-app", orale.WithArgs([]string{}), orale.WithEnviron([]string{}), orale.WithWorkingDir(filepath.Join(testAssetsPath, format+"-dir")))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testConf := TestConfig{}
+			conf.MustGet("", &testConf)
+
+			if testConf.Name != "legacy service" {
+				t.Fatalf("%s: expected Name to be legacy service, got %q", format, testConf.Name)
+			}
+			if testConf.Db.Host != "localhost" {
+				t.Fatalf("%s: expected Db.Host to be localhost, got %q", format, testConf.Db.Host)
+			}
+			if testConf.Db.MaxConns != 10 {
+				t.Fatalf("%s: expected Db.MaxConns to be 10, got %d", format, testConf.Db.MaxConns)
+			}
+			if testConf.Db.Replica.Host != "replica.local" {
+				t.Fatalf("%s: expected Db.Replica.Host to be replica.local, got %q", format, testConf.Db.Replica.Host)
+			}
+			if len(testConf.Channels) != 2 || testConf.Channels[1].Name != "News" {
+				t.Fatalf("%s: expected Channels[1].Name to be News, got %v", format, testConf.Channels)
+			}
+			if format == "ini" {
+				if len(testConf.Tags) != 3 || testConf.Tags[2] != "c" {
+					t.Fatalf("%s: expected Tags to be [a b c], got %v", format, testConf.Tags)
+				}
+				if testConf.TagStr != "a, b, c" {
+					t.Fatalf("%s: expected TagStr to be a, b, c, got %q", format, testConf.TagStr)
+				}
+				if len(testConf.Quoted) != 1 || testConf.Quoted[0] != "one, two" {
+					t.Fatalf("%s: expected Quoted to be [one, two], got %v", format, testConf.Quoted)
+				}
+				if conf.ConfigurationFiles()[0].Values["servers.hosts[1]"][0] != "beta" {
+					t.Fatalf("%s: expected servers.hosts[1] to be beta, got %v", format, conf.ConfigurationFiles()[0].Values["servers.hosts[1]"])
+				}
+			} else {
+				if len(testConf.Tags) != 2 || testConf.Tags[1] != "b, c" {
+					t.Fatalf("%s: expected Tags to be [a, b, c], got %v", format, testConf.Tags)
+				}
+				if conf.ConfigurationFiles()[0].Values["greeting"][0] != "hello world !" {
+					t.Fatalf("%s: expected greeting to be hello world !, got %q", format, conf.ConfigurationFiles()[0].Values["greeting"])
+				}
+			}
+		}
+	})
+
+	t.Run("should load explicitly given configuration files with precedence over discovered files", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"project/app.config.toml": {Data: []byte("a = \"discovered\"\nb = \"discovered\"\nc = \"discovered\"")},
+			"mnt/config/first.yaml":   {Data: []byte("a: first\nb: first")},
+			"mnt/config/second.json":  {Data: []byte(`{"a": "second"}`)},
+			"etc/app/from-env.ini":    {Data: []byte("d = env")},
+		}
+
+		type TestConfig struct {
+			A string `config:"a"`
+			B string `config:"b"`
+			C string `config:"c"`
+			D string `config:"d"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-file=/mnt/config/first.yaml", "--config-file", "../mnt/config/second.json"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.ConfigurationFiles()) != 3 {
+			t.Fatalf("expected 3 configuration files, got %d", len(conf.ConfigurationFiles()))
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "second" {
+			t.Fatalf("expected A to be second, got %s", testConf.A)
+		}
+		if testConf.B != "first" {
+			t.Fatalf("expected B to be first, got %s", testConf.B)
+		}
+		if testConf.C != "discovered" {
+			t.Fatalf("expected C to be discovered, got %s", testConf.C)
+		}
+
+		conf, err = orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{"APP__CONFIG_FILE=/etc/app/from-env.ini"}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf = TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.D != "env" {
+			t.Fatalf("expected D to be env, got %s", testConf.D)
+		}
+	})
+
+	t.Run("should only split configuration file paths given by environment variable", func(t *testing.T) {
+		t.Parallel()
+
+		listSeparator := string(filepath.ListSeparator)
+		fileSystem := fstest.MapFS{
+			"dir" + listSeparator + "x/c.toml": {Data: []byte(`a = "flag"`)},
+			"first.toml":                       {Data: []byte(`a = "first"`)},
+			"second.toml":                      {Data: []byte(`b = "second"`)},
+		}
+
+		type TestConfig struct {
+			A string `config:"a"`
+			B string `config:"b"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-file=dir" + listSeparator + "x/c.toml"}),
+			orale.WithEnviron([]string{"APP__CONFIG_FILE=first.toml" + listSeparator + "second.toml"}),
+			orale.WithFS(fileSystem),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "flag" {
+			t.Fatalf("expected A to be flag, got %s", testConf.A)
+		}
+		if testConf.B != "" {
+			t.Fatalf("expected the environment variable to be ignored, got B %s", testConf.B)
+		}
+
+		conf, err = orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{"APP__CONFIG_FILE=first.toml" + listSeparator + "second.toml"}),
+			orale.WithFS(fileSystem),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf = TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "first" || testConf.B != "second" {
+			t.Fatalf("expected A to be first and B to be second, got %s and %s", testConf.A, testConf.B)
+		}
+	})
+
+	t.Run("should return an error when an explicitly given configuration file is missing", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-file=missing.toml"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+		)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("should load a configuration file from stdin", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"project/app.config.toml": {Data: []byte("a = \"discovered\"\nb = \"discovered\"")},
+			"project/shared.yaml":     {Data: []byte("c: included")},
+		}
+
+		type TestConfig struct {
+			A string `config:"a"`
+			B string `config:"b"`
+			C string `config:"c"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-file=-", "--config-format=json"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+			orale.WithStdin(strings.NewReader(`{"a": "stdin", "include": ["shared.yaml"]}`)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.ConfigurationFiles()[0].Path != "-" {
+			t.Fatalf("expected first configuration file to be -, got %s", conf.ConfigurationFiles()[0].Path)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "stdin" {
+			t.Fatalf("expected A to be stdin, got %s", testConf.A)
+		}
+		if testConf.B != "discovered" {
+			t.Fatalf("expected B to be discovered, got %s", testConf.B)
+		}
+		if testConf.C != "included" {
+			t.Fatalf("expected C to be included, got %s", testConf.C)
+		}
+
+		_, err = orale.Load("app",
+			orale.WithArgs([]string{"--config-file=-", "--config-file=-"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithStdin(strings.NewReader("a = 1")),
+		)
+		if err == nil {
+			t.Fatal("expected an error when stdin is given more than once")
+		}
+	})
+
+	t.Run("should layer environment configuration files over base configuration files", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"app.config.toml":                 {Data: []byte("root = \"base\"\nregion = \"root\"")},
+			"project/app.config.toml":         {Data: []byte("a = \"base\"\nb = \"base\"\nc = \"base\"")},
+			"project/app.staging.config.yaml": {Data: []byte("b: staging\nc: staging")},
+			"project/app.eu.config.json":      {Data: []byte(`{"c": "eu"}`)},
+		}
+
+		type TestConfig struct {
+			A      string `config:"a"`
+			B      string `config:"b"`
+			C      string `config:"c"`
+			Root   string `config:"root"`
+			Region string `config:"region"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-environment=staging,eu"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.ConfigurationFiles()) != 4 {
+			t.Fatalf("expected 4 configuration files, got %d", len(conf.ConfigurationFiles()))
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "base" {
+			t.Fatalf("expected A to be base, got %s", testConf.A)
+		}
+		if testConf.B != "staging" {
+			t.Fatalf("expected B to be staging, got %s", testConf.B)
+		}
+		if testConf.C != "eu" {
+			t.Fatalf("expected C to be eu, got %s", testConf.C)
+		}
+		if testConf.Root != "base" {
+			t.Fatalf("expected Root to be base, got %s", testConf.Root)
+		}
+	})
+
+	t.Run("should load configuration files from user and system locations when enabled", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"project/app.config.toml":              {Data: []byte("a = \"project\"")},
+			"xdg/app/app.config.toml":              {Data: []byte("a = \"xdg\"\nb = \"xdg\"")},
+			"home/user/.config/app/app.config.yml": {Data: []byte("b: home-config\nc: home-config")},
+			"home/user/.app.config.json":           {Data: []byte(`{"c": "home", "d": "home"}`)},
+			"etc/app/app.config.toml":              {Data: []byte("d = \"etc\"\ne = \"etc\"")},
+		}
+
+		type TestConfig struct {
+			A string `config:"a"`
+			B string `config:"b"`
+			C string `config:"c"`
+			D string `config:"d"`
+			E string `config:"e"`
+		}
+
+		environ := []string{"HOME=/home/user", "XDG_CONFIG_HOME=/xdg"}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron(environ),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(conf.ConfigurationFiles()) != 1 {
+			t.Fatalf("expected 1 configuration file without user or system config, got %d", len(conf.ConfigurationFiles()))
+		}
+
+		conf, err = orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron(environ),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+			orale.WithUserConfig(),
+			orale.WithSystemConfig(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedSearchPaths := []string{"project", ".", "xdg/app", "home/user/.config/app", "home/user", "etc/app"}
+		if len(conf.SearchPaths) != len(expectedSearchPaths) {
+			t.Fatalf("expected search paths %v, got %v", expectedSearchPaths, conf.SearchPaths)
+		}
+		for i, expectedSearchPath := range expectedSearchPaths {
+			if conf.SearchPaths[i] != expectedSearchPath {
+				t.Fatalf("expected search paths %v, got %v", expectedSearchPaths, conf.SearchPaths)
+			}
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "project" {
+			t.Fatalf("expected A to be project, got %s", testConf.A)
+		}
+		if testConf.B != "xdg" {
+			t.Fatalf("expected B to be xdg, got %s", testConf.B)
+		}
+		if testConf.C != "home-config" {
+			t.Fatalf("expected C to be home-config, got %s", testConf.C)
+		}
+		if testConf.D != "home" {
+			t.Fatalf("expected D to be home, got %s", testConf.D)
+		}
+		if testConf.E != "etc" {
+			t.Fatalf("expected E to be etc, got %s", testConf.E)
+		}
+	})
+
+	t.Run("should stop searching parent directories at search boundaries", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"home/app.config.toml":                   {Data: []byte("a = \"home\"")},
+			"home/repo/.git/HEAD":                    {Data: []byte("ref: refs/heads/main")},
+			"home/repo/app.config.toml":              {Data: []byte("b = \"repo\"")},
+			"home/repo/service/go.mod":               {Data: []byte("module service")},
+			"home/repo/service/cmd/app.config.toml":  {Data: []byte("c = \"cmd\"")},
+			"home/repo/service/cmd/main/placeholder": {Data: []byte("")},
+		}
+
+		load := func(opts ...orale.LoadOption) *orale.Loader {
+			opts = append([]orale.LoadOption{
+				orale.WithArgs([]string{}),
+				orale.WithEnviron([]string{}),
+				orale.WithFS(fileSystem),
+				orale.WithWorkingDir("home/repo/service/cmd/main"),
+			}, opts...)
+			conf, err := orale.Load("app", opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return conf
+		}
+
+		conf := load()
+		if len(conf.ConfigurationFiles()) != 3 {
+			t.Fatalf("expected 3 configuration files, got %d", len(conf.ConfigurationFiles()))
+		}
+		if conf.SearchBoundary.Reason != orale.SearchStoppedAtRoot || conf.SearchBoundary.Path != "." {
+			t.Fatalf("expected search to stop at the root, got %+v", conf.SearchBoundary)
+		}
+
+		conf = load(orale.WithSearchBoundary(".git"))
+		if len(conf.ConfigurationFiles()) != 2 {
+			t.Fatalf("expected 2 configuration files, got %d", len(conf.ConfigurationFiles()))
+		}
+		if conf.SearchBoundary.Reason != orale.SearchStoppedAtMarker || conf.SearchBoundary.Path != "home/repo" || conf.SearchBoundary.Marker != ".git" {
+			t.Fatalf("expected search to stop at the .git marker in home/repo, got %+v", conf.SearchBoundary)
+		}
+
+		conf = load(orale.WithSearchBoundary(".git", "go.mod"))
+		if len(conf.ConfigurationFiles()) != 1 {
+			t.Fatalf("expected 1 configuration file, got %d", len(conf.ConfigurationFiles()))
+		}
+		if conf.SearchBoundary.Path != "home/repo/service" || conf.SearchBoundary.Marker != "go.mod" {
+			t.Fatalf("expected search to stop at the go.mod marker in home/repo/service, got %+v", conf.SearchBoundary)
+		}
+
+		conf = load(orale.WithMaxSearchDepth(1))
+		if len(conf.ConfigurationFiles()) != 1 || len(conf.SearchPaths) != 2 {
+			t.Fatalf("expected 1 configuration file in 2 search paths, got %d in %v", len(conf.ConfigurationFiles()), conf.SearchPaths)
+		}
+		if conf.SearchBoundary.Reason != orale.SearchStoppedAtMaxDepth || conf.SearchBoundary.Path != "home/repo/service/cmd" {
+			t.Fatalf("expected search to stop at max depth in home/repo/service/cmd, got %+v", conf.SearchBoundary)
+		}
+
+		conf = load(orale.WithStopAtFirstMatch())
+		if len(conf.ConfigurationFiles()) != 1 {
+			t.Fatalf("expected 1 configuration file, got %d", len(conf.ConfigurationFiles()))
+		}
+		if conf.SearchBoundary.Reason != orale.SearchStoppedAtFirstMatch || conf.SearchBoundary.Path != "home/repo/service/cmd" {
+			t.Fatalf("expected search to stop at the first match in home/repo/service/cmd, got %+v", conf.SearchBoundary)
+		}
+	})
+
+	t.Run("should load drop-in configuration files in lexical order", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"app.config.toml":                {Data: []byte("a = \"main\"\nb = \"main\"\nc = \"main\"\nd = \"main\"")},
+			"app.config.d/10-first.toml":     {Data: []byte("b = \"first\"\nc = \"first\"")},
+			"app.config.d/20-second.yaml":    {Data: []byte("c: second")},
+			"app.config.d/README.md":         {Data: []byte("not configuration")},
+			"app.config.d/.30-hidden.toml":   {Data: []byte("c = \"hidden\"")},
+			"app.config.d/nested/40.toml":    {Data: []byte("c = \"nested\"")},
+			"app.test.config.toml":           {Data: []byte("d = \"test\"\ne = \"test\"")},
+			"app.test.config.d/10-drop.toml": {Data: []byte("e = \"test-drop\"")},
+		}
+
+		type TestConfig struct {
+			A string `config:"a"`
+			B string `config:"b"`
+			C string `config:"c"`
+			D string `config:"d"`
+			E string `config:"e"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-environment=test"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedPaths := []string{
+			"app.test.config.d/10-drop.toml",
+			"app.test.config.toml",
+			"app.config.d/20-second.yaml",
+			"app.config.d/10-first.toml",
+			"app.config.toml",
+		}
+		if len(conf.ConfigurationFiles()) != len(expectedPaths) {
+			t.Fatalf("expected %d configuration files, got %d", len(expectedPaths), len(conf.ConfigurationFiles()))
+		}
+		for i, expectedPath := range expectedPaths {
+			if conf.ConfigurationFiles()[i].Path != expectedPath {
+				t.Fatalf("expected configuration file %d to be %s, got %s", i, expectedPath, conf.ConfigurationFiles()[i].Path)
+			}
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "main" {
+			t.Fatalf("expected A to be main, got %s", testConf.A)
+		}
+		if testConf.B != "first" {
+			t.Fatalf("expected B to be first, got %s", testConf.B)
+		}
+		if testConf.C != "second" {
+			t.Fatalf("expected C to be second, got %s", testConf.C)
+		}
+		if testConf.D != "test" {
+			t.Fatalf("expected D to be test, got %s", testConf.D)
+		}
+		if testConf.E != "test-drop" {
+			t.Fatalf("expected E to be test-drop, got %s", testConf.E)
+		}
+	})
+
+	t.Run("should load embedded defaults with the lowest precedence", func(t *testing.T) {
+		t.Parallel()
+
+		defaults, err := fs.Sub(embeddedDefaults, "test-assets/defaults-dir")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fileSystem := fstest.MapFS{
+			"project/defaults-app.config.toml": {Data: []byte("[server]\nport = 9000")},
+		}
+
+		type TestConfig struct {
+			Name   string `config:"name"`
+			Server struct {
+				Host string `config:"host"`
+				Port int    `config:"port"`
+			} `config:"server"`
+			Logging struct {
+				Level string `config:"level"`
+			} `config:"logging"`
+		}
+
+		conf, err := orale.Load("defaultsApp",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{"DEFAULTS_APP__NAME=environment"}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+			orale.WithDefaults(defaults),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.ConfigurationFiles()) != 3 {
+			t.Fatalf("expected 3 configuration files, got %d", len(conf.ConfigurationFiles()))
+		}
+		if conf.ConfigurationFiles()[2].Path != "shared/logging.toml" {
+			t.Fatalf("expected included default file to be shared/logging.toml, got %s", conf.ConfigurationFiles()[2].Path)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Name != "environment" {
+			t.Fatalf("expected Name to be environment, got %s", testConf.Name)
+		}
+		if testConf.Server.Host != "0.0.0.0" {
+			t.Fatalf("expected Server.Host to be 0.0.0.0, got %s", testConf.Server.Host)
+		}
+		if testConf.Server.Port != 9000 {
+			t.Fatalf("expected Server.Port to be 9000, got %d", testConf.Server.Port)
+		}
+		if testConf.Logging.Level != "info" {
+			t.Fatalf("expected Logging.Level to be info, got %s", testConf.Logging.Level)
+		}
+	})
 }
 
 func TestLoadFromValues(t *testing.T) {
@@ -214,24 +956,24 @@ func TestLoadFromValues(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if len(conf.FlagValues) != 4 {
-			t.Fatalf("expected 4 flag values, got %d", len(conf.FlagValues))
+		if len(conf.FlagValues()) != 4 {
+			t.Fatalf("expected 4 flag values, got %d", len(conf.FlagValues()))
 		}
 
-		if conf.FlagValues["flag1"][0] != "value1" {
-			t.Fatalf("expected flag1 to be value1, got %s", conf.FlagValues["flag1"])
+		if conf.FlagValues()["flag1"][0] != "value1" {
+			t.Fatalf("expected flag1 to be value1, got %s", conf.FlagValues()["flag1"])
 		}
 
-		if conf.FlagValues["flag2"][0] != "value2" {
-			t.Fatalf("expected flag2 to be value2, got %s", conf.FlagValues["flag2"])
+		if conf.FlagValues()["flag2"][0] != "value2" {
+			t.Fatalf("expected flag2 to be value2, got %s", conf.FlagValues()["flag2"])
 		}
 
-		if conf.FlagValues["f"][0] != "value3" {
-			t.Fatalf("expected f to be value3, got %s", conf.FlagValues["f"])
+		if conf.FlagValues()["f"][0] != "value3" {
+			t.Fatalf("expected f to be value3, got %s", conf.FlagValues()["f"])
 		}
 
-		if conf.FlagValues["g"][0] != "value4" {
-			t.Fatalf("expected g to be value4, got %s", conf.FlagValues["g"])
+		if conf.FlagValues()["g"][0] != "value4" {
+			t.Fatalf("expected g to be value4, got %s", conf.FlagValues()["g"])
 		}
 	})
 
@@ -248,16 +990,16 @@ func TestLoadFromValues(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if len(conf.EnvironmentValues) != 2 {
-			t.Fatalf("expected 2 environment values, got %d", len(conf.EnvironmentValues))
+		if len(conf.EnvironmentValues()) != 2 {
+			t.Fatalf("expected 2 environment values, got %d", len(conf.EnvironmentValues()))
 		}
 
-		if conf.EnvironmentValues["env1"][0] != "value1" {
-			t.Fatalf("expected env1 to be value1, got %s", conf.EnvironmentValues["env1"])
+		if conf.EnvironmentValues()["env1"][0] != "value1" {
+			t.Fatalf("expected env1 to be value1, got %s", conf.EnvironmentValues()["env1"])
 		}
 
-		if conf.EnvironmentValues["env2"][0] != "value2" {
-			t.Fatalf("expected env2 to be value2, got %s", conf.EnvironmentValues["env2"])
+		if conf.EnvironmentValues()["env2"][0] != "value2" {
+			t.Fatalf("expected env2 to be value2, got %s", conf.EnvironmentValues()["env2"])
 		}
 	})
 
@@ -275,35 +1017,35 @@ func TestLoadFromValues(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if len(conf.ConfigurationFiles) != 2 {
-			t.Fatalf("expected 2 configuration files, got %d", len(conf.ConfigurationFiles))
+		if len(conf.ConfigurationFiles()) != 2 {
+			t.Fatalf("expected 2 configuration files, got %d", len(conf.ConfigurationFiles()))
 		}
 
 		config1Path := filepath.Join(testAssetsPath, "search-dir/test-1.config.toml")
 		config2Path := filepath.Join(testAssetsPath, "test-2.config.toml")
 
-		if len(conf.ConfigurationFiles) != 2 {
-			t.Fatalf("expected 2 values in test_config, got %d", len(conf.ConfigurationFiles))
+		if len(conf.ConfigurationFiles()) != 2 {
+			t.Fatalf("expected 2 values in test_config, got %d", len(conf.ConfigurationFiles()))
 		}
 
-		if conf.ConfigurationFiles[0].Path != config1Path {
-			t.Fatalf("expected first configuration file path to be %x, got %x", config1Path, conf.ConfigurationFiles[0].Path)
+		if conf.ConfigurationFiles()[0].Path != config1Path {
+			t.Fatalf("expected first configuration file path to be %x, got %x", config1Path, conf.ConfigurationFiles()[0].Path)
 		}
-		if conf.ConfigurationFiles[0].Values["testVal1"][0] != int64(1) {
-			t.Fatalf("expected testVal1 to be 3, got %s", conf.ConfigurationFiles[0].Values["testVal1"])
+		if conf.ConfigurationFiles()[0].Values["testVal1"][0] != int64(1) {
+			t.Fatalf("expected testVal1 to be 3, got %s", conf.ConfigurationFiles()[0].Values["testVal1"])
 		}
-		if conf.ConfigurationFiles[0].Values["testVal2"][0] != int64(2) {
-			t.Fatalf("expected testVal3 to be 4, got %s", conf.ConfigurationFiles[0].Values["testVal2"])
+		if conf.ConfigurationFiles()[0].Values["testVal2"][0] != int64(2) {
+			t.Fatalf("expected testVal3 to be 4, got %s", conf.ConfigurationFiles()[0].Values["testVal2"])
 		}
 
-		if conf.ConfigurationFiles[1].Path != config2Path {
-			t.Fatalf("expected second configuration file path to be %s, got %s", config2Path, conf.ConfigurationFiles[1].Path)
+		if conf.ConfigurationFiles()[1].Path != config2Path {
+			t.Fatalf("expected second configuration file path to be %s, got %s", config2Path, conf.ConfigurationFiles()[1].Path)
 		}
-		if conf.ConfigurationFiles[1].Values["testVal1"][0] != int64(3) {
-			t.Fatalf("expected testVal1 to be 1, got %s", conf.ConfigurationFiles[1].Values["testVal1"])
+		if conf.ConfigurationFiles()[1].Values["testVal1"][0] != int64(3) {
+			t.Fatalf("expected testVal1 to be 1, got %s", conf.ConfigurationFiles()[1].Values["testVal1"])
 		}
-		if conf.ConfigurationFiles[1].Values["testVal3"][0] != int64(4) {
-			t.Fatalf("expected testVal2 to be 2, got %s", conf.ConfigurationFiles[1].Values["testVal3"])
+		if conf.ConfigurationFiles()[1].Values["testVal3"][0] != int64(4) {
+			t.Fatalf("expected testVal2 to be 2, got %s", conf.ConfigurationFiles()[1].Values["testVal3"])
 		}
 	})
 
@@ -326,24 +1068,24 @@ func TestLoadFromValues(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if len(conf.FlagValues) != 2 {
-			t.Fatalf("expected 2 flag values, got %d", len(conf.FlagValues))
+		if len(conf.FlagValues()) != 2 {
+			t.Fatalf("expected 2 flag values, got %d", len(conf.FlagValues()))
 		}
 
-		if conf.FlagValues["flag"][0] != "value1" && conf.FlagValues["flag"][1] != "value2" {
-			t.Fatalf("expected flag to be value1 and value2, got %v", conf.FlagValues["flag"])
+		if conf.FlagValues()["flag"][0] != "value1" && conf.FlagValues()["flag"][1] != "value2" {
+			t.Fatalf("expected flag to be value1 and value2, got %v", conf.FlagValues()["flag"])
 		}
 
-		if conf.FlagValues["f"][0] != "value3" && conf.FlagValues["f"][1] != "value4" {
-			t.Fatalf("expected f to be value3 and value4, got %v", conf.FlagValues["f"])
+		if conf.FlagValues()["f"][0] != "value3" && conf.FlagValues()["f"][1] != "value4" {
+			t.Fatalf("expected f to be value3 and value4, got %v", conf.FlagValues()["f"])
 		}
 
-		if len(conf.EnvironmentValues) != 1 {
-			t.Fatalf("expected 1 environment value, got %d", len(conf.EnvironmentValues))
+		if len(conf.EnvironmentValues()) != 1 {
+			t.Fatalf("expected 1 environment value, got %d", len(conf.EnvironmentValues()))
 		}
 
-		if conf.EnvironmentValues["env"][0] != "value1" && conf.EnvironmentValues["env"][1] != "value2" {
-			t.Fatalf("expected env to be value1 and value2, got %v", conf.EnvironmentValues["env"])
+		if conf.EnvironmentValues()["env"][0] != "value1" && conf.EnvironmentValues()["env"][1] != "value2" {
+			t.Fatalf("expected env to be value1 and value2, got %v", conf.EnvironmentValues()["env"])
 		}
 	})
 }
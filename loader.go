@@ -3,11 +3,95 @@ package orale
 // Loader is a struct that contains all the values loaded from flags, environment
 // variables, and configuration files. It can be used to marshal the values into
 // a struct.
+//
+// Values are held in Sources. The FlagValues, EnvironmentValues, and
+// ConfigurationFiles fields of earlier versions are now methods of the same
+// names, and a Loader built as a struct literal should list its values in
+// Sources instead, for example with NewMapSource.
 type Loader struct {
-	// FlagValues is a map of flag values by path.
-	FlagValues map[string][]any
-	// EnvironmentValues is a map of environment variable values by path.
-	EnvironmentValues map[string][]any
-	// ConfigurationFiles is a slice of configuration files.
-	ConfigurationFiles []*File
+	// Sources is the list of sources values are resolved from. Sources with a
+	// higher priority take precedence, and sources with equal priority are
+	// consulted in the order they appear. Custom sources may be appended.
+	Sources []Source
+	// SearchPaths lists the directories that were searched for configuration
+	// files in order of precedence.
+	SearchPaths []string
+	// SearchBoundary describes where and why the search for configuration files
+	// in the working directory and its parents stopped.
+	SearchBoundary SearchBoundary
+	// FlagOrigins records where each flag value came from by path, in the same
+	// order as the values. This identifies values read from response files.
+	FlagOrigins map[string][]ArgumentOrigin
+	// Args holds the positional arguments, which are the program arguments
+	// that are neither flags nor flag values, along with every argument after
+	// `--`. Fields tagged with `arg=<index>` or `args` are set from them.
+	Args []string
+	// Command holds the names of the command selected by the first positional
+	// arguments and its parents, such as `["db", "migrate"]`. It is empty when
+	// no command was given. See WithCommands.
+	Command []string
+	// HelpRequested is true when `--help` or `-h` was given. The application
+	// should print the text returned by Usage and exit.
+	HelpRequested bool
+	// CompletionShell is the shell named by `--completion=<shell>`, or empty if
+	// no completion script was requested. The application should print the
+	// script returned by Completion and exit.
+	CompletionShell string
+
+	name       string
+	envPrefix  string
+	commands   []*Command
+	flagTarget flagTarget
+}
+
+// CommandPath returns the path the selected command's values are scoped under,
+// such as `db.migrate`, for use with Get. It is empty when no command was
+// given.
+func (l *Loader) CommandPath() string {
+	return commandPath(l.Command)
+}
+
+// FlagValues returns a map of flag values by path.
+func (l *Loader) FlagValues() map[string][]any {
+	return l.mapSourceValues(FlagSourceName)
+}
+
+// EnvironmentValues returns a map of environment variable values by path.
+func (l *Loader) EnvironmentValues() map[string][]any {
+	return l.mapSourceValues(EnvironmentSourceName)
+}
+
+// SecretValues returns a map of values loaded from secrets directories by path.
+func (l *Loader) SecretValues() map[string][]any {
+	return l.mapSourceValues(SecretsSourceName)
+}
+
+// DotenvValues returns a map of values loaded from dotenv files by path.
+func (l *Loader) DotenvValues() map[string][]any {
+	return l.mapSourceValues(DotenvSourceName)
+}
+
+// ConfigurationFiles returns the configuration files held by the loader in the
+// order they were loaded, including any embedded defaults.
+func (l *Loader) ConfigurationFiles() []*File {
+	files := []*File{}
+	for _, source := range l.Sources {
+		if file, ok := source.(*File); ok {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func (l *Loader) mapSourceValues(name string) map[string][]any {
+	for _, source := range l.Sources {
+		if mapSource, ok := source.(*MapSource); ok && mapSource.Name() == name {
+			return mapSource.FlatValues()
+		}
+	}
+	return map[string][]any{}
+}
+
+func (l *Loader) orderedSources() []Source {
+	return sortSources(l.Sources)
 }
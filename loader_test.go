@@ -52,3 +52,153 @@ func TestLoadAndGetConfig(t *testing.T) {
 		}
 	})
 }
+
+func TestLoaderSources(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should resolve values from custom sources according to their priority", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			A string   `config:"a"`
+			B string   `config:"b"`
+			C []string `config:"c"`
+		}
+
+		loader, err := orale.LoadFromValues([]string{"--a=flag", "--b=flag"}, "", []string{}, "", []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		loader.Sources = append(loader.Sources,
+			orale.NewMapSource("locked", orale.FlagPriority+1, map[string][]any{
+				"a":    {"locked"},
+				"c[0]": {"locked"},
+			}),
+			orale.NewMapSource("defaults", orale.FilePriority-1, map[string][]any{
+				"b":    {"default"},
+				"c[0]": {"default-1"},
+				"c[1]": {"default-2"},
+			}),
+		)
+
+		var testConfig TestConfig
+		if err := loader.Get("", &testConfig); err != nil {
+			t.Fatal(err)
+		}
+
+		if testConfig.A != "locked" {
+			t.Fatalf("expected A to be locked, got %s", testConfig.A)
+		}
+		if testConfig.B != "flag" {
+			t.Fatalf("expected B to be flag, got %s", testConfig.B)
+		}
+		if len(testConfig.C) != 1 || testConfig.C[0] != "locked" {
+			t.Fatalf("expected C to be [locked], got %v", testConfig.C)
+		}
+	})
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	t.Parallel()
+
+	type TestConfig struct {
+		TestVal1 int   `config:"testVal1"`
+		TestVal3 int   `config:"testVal3"`
+		List     []int `config:"list"`
+	}
+
+	programArgs := []string{"--test-val-1=100", "--list[0]=100"}
+	envVars := []string{"TEST__TEST_VAL_1=200", "TEST__TEST_VAL_3=200", "TEST__LIST[0]=200", "TEST__LIST[1]=200"}
+	configFileNames := []string{"test-2"}
+
+	t.Run("should prefer flags, then environment variables, then files by default", func(t *testing.T) {
+		t.Parallel()
+
+		loader, err := orale.LoadFromValues(programArgs, "TEST", envVars, testAssetsPath, configFileNames)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var testConfig TestConfig
+		loader.MustGetAll(&testConfig)
+
+		if testConfig.TestVal1 != 100 {
+			t.Fatalf("expected TestVal1 to be 100, got %d", testConfig.TestVal1)
+		}
+		if testConfig.TestVal3 != 200 {
+			t.Fatalf("expected TestVal3 to be 200, got %d", testConfig.TestVal3)
+		}
+		if len(testConfig.List) != 1 {
+			t.Fatalf("expected List to have 1 value, got %d", len(testConfig.List))
+		}
+	})
+
+	t.Run("should honour a custom precedence", func(t *testing.T) {
+		t.Parallel()
+
+		loader, err := orale.LoadFromValues(programArgs, "TEST", envVars, testAssetsPath, configFileNames,
+			orale.WithPrecedence(orale.Files, orale.Environment),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var testConfig TestConfig
+		loader.MustGetAll(&testConfig)
+
+		if testConfig.TestVal1 != 3 {
+			t.Fatalf("expected TestVal1 to be 3, got %d", testConfig.TestVal1)
+		}
+		if testConfig.TestVal3 != 4 {
+			t.Fatalf("expected TestVal3 to be 4, got %d", testConfig.TestVal3)
+		}
+		if len(testConfig.List) != 2 || testConfig.List[0] != 200 {
+			t.Fatalf("expected List to be [200 200], got %v", testConfig.List)
+		}
+	})
+
+	t.Run("should honour explicit priorities and custom sources", func(t *testing.T) {
+		t.Parallel()
+
+		loader, err := orale.LoadFromValues(programArgs, "TEST", envVars, testAssetsPath, configFileNames,
+			orale.WithPriority(orale.Environment, orale.FlagPriority+1),
+			orale.WithSources(orale.NewMapSource("locked", orale.FlagPriority+2, map[string][]any{
+				"testVal3": {300},
+			})),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var testConfig TestConfig
+		loader.MustGetAll(&testConfig)
+
+		if testConfig.TestVal1 != 200 {
+			t.Fatalf("expected TestVal1 to be 200, got %d", testConfig.TestVal1)
+		}
+		if testConfig.TestVal3 != 300 {
+			t.Fatalf("expected TestVal3 to be 300, got %d", testConfig.TestVal3)
+		}
+	})
+
+	t.Run("should select the configuration environment using the same precedence", func(t *testing.T) {
+		t.Parallel()
+
+		loader, err := orale.Load("testApplication",
+			orale.WithArgs([]string{"--config-environment=missing"}),
+			orale.WithEnviron([]string{"TEST_APPLICATION__CONFIG_ENVIRONMENT=test"}),
+			orale.WithWorkingDir(testAssetsPath),
+			orale.WithPrecedence(orale.Environment),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var testConfig TestConfig
+		loader.MustGetAll(&testConfig)
+
+		if testConfig.TestVal1 != 10 {
+			t.Fatalf("expected TestVal1 to be 10, got %d", testConfig.TestVal1)
+		}
+	})
+}
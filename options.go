@@ -0,0 +1,281 @@
+package orale
+
+import (
+	"io"
+	"io/fs"
+)
+
+// LoadOption configures a call to Load or LoadFromValues.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	name                string
+	args                []string
+	target              any
+	commands            []*Command
+	singleDashLongFlags bool
+	responseFiles       bool
+	environ             []string
+	stdin               io.Reader
+	workingDir          string
+	fileNames           []string
+	fileNameTemplates   []string
+	envPrefix           string
+	fileSystem          fs.FS
+	defaultFileSystems  []fs.FS
+	dotenv              bool
+	secretsDirs         []string
+	boundaryMarkers     []string
+	maxSearchDepth      int
+	limitSearchDepth    bool
+	stopAtFirstMatch    bool
+	userConfig          bool
+	systemConfig        bool
+	precedence          []SourceKind
+	priorities          map[SourceKind]int
+	sources             []Source
+}
+
+// WithArgs sets the program arguments flags are parsed from. The arguments
+// should not include the program name. Defaults to `os.Args[1:]`.
+func WithArgs(args []string) LoadOption {
+	return func(o *loadOptions) {
+		o.args = args
+	}
+}
+
+// WithTarget tells the flag parser about the struct configuration will be read
+// into, usually the same pointer later passed to GetAll. Knowing the types of
+// the target's fields lets boolean flags such as `--verbose` be given without a
+// value even when followed by a positional argument, as otherwise
+// `--verbose ./data` would set verbose to `./data`.
+func WithTarget(target any) LoadOption {
+	return func(o *loadOptions) {
+		o.target = target
+	}
+}
+
+// WithCommands defines the commands which may be selected by the first
+// positional arguments, as in `tool db migrate --dry-run`. The selected command
+// is available as Loader.Command, and its values can be read with
+// `Get(loader.CommandPath(), &commandConfig)`. The remaining positional
+// arguments are left in Loader.Args.
+func WithCommands(commands ...*Command) LoadOption {
+	return func(o *loadOptions) {
+		o.commands = append(o.commands, commands...)
+	}
+}
+
+// WithSingleDashLongFlags treats arguments with a single leading dash, such as
+// `-verbose` or `-port=80`, as long flags in the style of Go's flag package,
+// rather than as groups of short flags.
+func WithSingleDashLongFlags() LoadOption {
+	return func(o *loadOptions) {
+		o.singleDashLongFlags = true
+	}
+}
+
+// WithResponseFiles enables or disables the expansion of `@path` arguments
+// into the arguments listed in the response file at path, one per line.
+// Response files are disabled by default, so arguments such as `@someone` are
+// left alone. Even when enabled, an argument taken as the value of the flag
+// before it is not expanded, so passing the config struct with WithTarget
+// helps tell the two apart.
+func WithResponseFiles(enabled bool) LoadOption {
+	return func(o *loadOptions) {
+		o.responseFiles = enabled
+	}
+}
+
+// WithEnviron sets the environment variables, in the same format as returned
+// by `os.Environ()`. Defaults to `os.Environ()`.
+func WithEnviron(environ []string) LoadOption {
+	return func(o *loadOptions) {
+		o.environ = environ
+	}
+}
+
+// WithStdin sets the reader a configuration file is read from when
+// `--config-file=-` is given. Defaults to `os.Stdin`.
+func WithStdin(stdin io.Reader) LoadOption {
+	return func(o *loadOptions) {
+		o.stdin = stdin
+	}
+}
+
+// WithWorkingDir sets the directory the search for configuration files starts
+// from. Defaults to the current working directory. When used together with
+// WithFS the directory is a path within the given file system.
+func WithWorkingDir(dir string) LoadOption {
+	return func(o *loadOptions) {
+		o.workingDir = dir
+	}
+}
+
+// WithFileNames sets the configuration file names to search for. Each name is
+// used without its extension, so `my-app` will match `my-app.config.toml`.
+// Defaults to the application name converted to kebab case.
+func WithFileNames(names ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.fileNames = names
+	}
+}
+
+// WithFileNameTemplates sets the templates used to name configuration files,
+// replacing DefaultFileNameTemplates. Templates name files without their
+// extension and may contain the placeholders {name}, {env}, {hostname},
+// {user}, {goos}, and {goarch}. Templates listed later take precedence over
+// templates listed earlier. For example, to add host specific files on top of
+// the defaults:
+//
+//	orale.WithFileNameTemplates(append(orale.DefaultFileNameTemplates, "{name}.{hostname}.config")...)
+func WithFileNameTemplates(templates ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.fileNameTemplates = templates
+	}
+}
+
+// WithEnvPrefix sets the prefix environment variables must have to be loaded.
+// Defaults to the application name converted to screaming snake case.
+func WithEnvPrefix(prefix string) LoadOption {
+	return func(o *loadOptions) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithFS sets the file system configuration files are read from. Paths within
+// the file system are slash separated and unrooted as described by io/fs.
+// Defaults to the operating system's file system.
+func WithFS(fileSystem fs.FS) LoadOption {
+	return func(o *loadOptions) {
+		o.fileSystem = fileSystem
+	}
+}
+
+// WithDefaults loads default configuration files from the root of the given
+// file system, typically an embed.FS compiled into the binary. The same file
+// names are looked for as in the working directory, and includes are resolved
+// within the given file system. Defaults have the lowest precedence of all
+// sources. File systems given earlier take precedence over later ones. Use
+// fs.Sub to load defaults from a subdirectory:
+//
+//	//go:embed defaults
+//	var defaultsFS embed.FS
+//
+//	defaults, _ := fs.Sub(defaultsFS, "defaults")
+//	conf, err := orale.Load("my-app", orale.WithDefaults(defaults))
+func WithDefaults(fileSystem fs.FS) LoadOption {
+	return func(o *loadOptions) {
+		o.defaultFileSystems = append(o.defaultFileSystems, fileSystem)
+	}
+}
+
+// WithDotenv enables or disables loading of `.env` and `.env.<environment>`
+// files. Dotenv files are searched for in the same directories as
+// configuration files, and are disabled by default so stray `.env` files in
+// parent directories aren't picked up unexpectedly. Consider limiting the
+// search with WithSearchBoundary when enabling them.
+func WithDotenv(enabled bool) LoadOption {
+	return func(o *loadOptions) {
+		o.dotenv = enabled
+	}
+}
+
+// WithSecretsDir loads values from directories holding one file per value,
+// such as `/run/secrets` for Docker secrets, `$CREDENTIALS_DIRECTORY` for
+// systemd credentials, or a mounted Kubernetes secret volume. File names are
+// mapped into paths like environment variable names without the prefix, so
+// `DB__PASSWORD` or `db/password` both become `db.password`. The trimmed
+// contents of each file are the value. Directories given earlier take
+// precedence, and missing directories are ignored. Secrets take precedence over
+// dotenv files, but not over environment variables.
+func WithSecretsDir(dirs ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.secretsDirs = append(o.secretsDirs, dirs...)
+	}
+}
+
+// WithSearchBoundary stops the search for configuration files in the working
+// directory's parents at the first directory containing any of the given
+// markers, such as `.git` or `go.mod`. The directory containing the marker is
+// still searched. This also applies to the search for dotenv files.
+func WithSearchBoundary(markers ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.boundaryMarkers = append(o.boundaryMarkers, markers...)
+	}
+}
+
+// WithMaxSearchDepth limits the search for configuration files to the working
+// directory and at most depth of its parents. A depth of 0 searches only the
+// working directory. This also applies to the search for dotenv files.
+func WithMaxSearchDepth(depth int) LoadOption {
+	return func(o *loadOptions) {
+		o.maxSearchDepth = depth
+		o.limitSearchDepth = true
+	}
+}
+
+// WithStopAtFirstMatch stops the search for configuration files in the
+// working directory's parents at the first directory containing a
+// configuration file. User and system locations are still searched.
+func WithStopAtFirstMatch() LoadOption {
+	return func(o *loadOptions) {
+		o.stopAtFirstMatch = true
+	}
+}
+
+// WithUserConfig adds the user's configuration locations to the search for
+// configuration files. These are `$XDG_CONFIG_HOME/<name>/`,
+// `~/.config/<name>/`, and dot files such as `~/.<name>.config.toml` in the
+// home directory, where name is each configuration file name. The same file
+// names are looked for as in the working directory. User locations take
+// precedence over system locations, but not over the working directory and its
+// parents.
+func WithUserConfig() LoadOption {
+	return func(o *loadOptions) {
+		o.userConfig = true
+	}
+}
+
+// WithSystemConfig adds the system wide configuration location `/etc/<name>/`
+// to the search for configuration files, where name is each configuration file
+// name. System locations have the lowest precedence of all discovered files.
+func WithSystemConfig() LoadOption {
+	return func(o *loadOptions) {
+		o.systemConfig = true
+	}
+}
+
+// WithPrecedence sets the precedence of the built in sources from highest to
+// lowest. For example `WithPrecedence(Environment, Flags, Files)` allows
+// environment variables to override flags. Kinds which are not listed keep
+// their default order below the listed kinds. The listed kinds take over the
+// default priority values, so custom sources keep their position relative to
+// the built in ones.
+func WithPrecedence(kinds ...SourceKind) LoadOption {
+	return func(o *loadOptions) {
+		o.precedence = kinds
+	}
+}
+
+// WithPriority sets the priority of a kind of built in source explicitly,
+// overriding both the default priority and WithPrecedence. See Source for
+// how priorities are used.
+func WithPriority(kind SourceKind, priority int) LoadOption {
+	return func(o *loadOptions) {
+		if o.priorities == nil {
+			o.priorities = map[SourceKind]int{}
+		}
+		o.priorities[kind] = priority
+	}
+}
+
+// WithSources adds custom sources to the loader. Each source's own priority
+// determines its precedence relative to the built in sources. For example a
+// source with a priority above FlagPriority will override all built in
+// sources with their default priorities.
+func WithSources(sources ...Source) LoadOption {
+	return func(o *loadOptions) {
+		o.sources = append(o.sources, sources...)
+	}
+}
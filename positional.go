@@ -0,0 +1,214 @@
+package orale
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const argTagOption = "arg"
+const argsTagOption = "args"
+const optionalTagOption = "optional"
+
+// Errors returned by Get when positional arguments don't fit the target.
+var (
+	ErrMissingArgument    = errors.New("missing argument")
+	ErrUnexpectedArgument = errors.New("unexpected argument")
+	ErrInvalidArgument    = errors.New("invalid argument")
+)
+
+// fieldTag is a parsed `config` struct tag. The tag holds the field's name
+// optionally followed by comma separated options. `arg=<index>` binds the
+// field to a positional argument, and `args` binds a slice field to the
+// positional arguments following the last indexed one. Positional arguments
+// bound with `arg` are required unless the `optional` option is also given.
+type fieldTag struct {
+	name     string
+	argIndex int
+	isArg    bool
+	isArgs   bool
+	optional bool
+}
+
+func parseFieldTag(structField reflect.StructField) (fieldTag, error) {
+	tagChunks := strings.Split(structField.Tag.Get("config"), ",")
+	tag := fieldTag{name: tagChunks[0]}
+	for _, option := range tagChunks[1:] {
+		optionName, optionValue, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch optionName {
+		case argTagOption:
+			argIndex, err := strconv.Atoi(optionValue)
+			if err != nil || argIndex < 0 {
+				return tag, fmt.Errorf("invalid config tag on field %s: %s=%s is not a valid argument index", structField.Name, argTagOption, optionValue)
+			}
+			tag.argIndex = argIndex
+			tag.isArg = true
+		case argsTagOption:
+			tag.isArgs = true
+		case optionalTagOption:
+			tag.optional = true
+		default:
+			return tag, fmt.Errorf("invalid config tag on field %s: unknown option %s", structField.Name, optionName)
+		}
+	}
+	return tag, nil
+}
+
+// positionalField is a field of the target bound to positional arguments.
+type positionalField struct {
+	name  string
+	tag   fieldTag
+	value reflect.Value
+}
+
+// getArgs sets the fields of target tagged with `arg=<index>` or `args` from
+// the loader's positional arguments. If the target has any such fields, every
+// positional argument must be bound to one of them. Fields without a matching
+// argument are left alone.
+func getArgs(l *Loader, target reflect.Value) error {
+	positionalFields := []positionalField{}
+	if err := collectPositionalFields(target, &positionalFields); err != nil {
+		return err
+	}
+	if len(positionalFields) == 0 {
+		return nil
+	}
+
+	restIndex := 0
+	var restField *positionalField
+	boundIndexes := map[int]string{}
+	for i, field := range positionalFields {
+		if field.tag.isArgs {
+			if restField != nil {
+				return fmt.Errorf("fields %s and %s are both tagged with %s", restField.name, field.name, argsTagOption)
+			}
+			restField = &positionalFields[i]
+			continue
+		}
+		if boundName, ok := boundIndexes[field.tag.argIndex]; ok {
+			return fmt.Errorf("fields %s and %s are both bound to argument %d", boundName, field.name, field.tag.argIndex)
+		}
+		boundIndexes[field.tag.argIndex] = field.name
+		if field.tag.argIndex+1 > restIndex {
+			restIndex = field.tag.argIndex + 1
+		}
+	}
+
+	for _, field := range positionalFields {
+		if field.tag.isArgs {
+			continue
+		}
+		if field.tag.argIndex >= len(l.Args) {
+			if !field.tag.optional {
+				return fmt.Errorf("%w %d for %s", ErrMissingArgument, field.tag.argIndex, field.name)
+			}
+			continue
+		}
+		if err := setArgValue(field.value, l.Args[field.tag.argIndex]); err != nil {
+			return fmt.Errorf("%w %d for %s: %w", ErrInvalidArgument, field.tag.argIndex, field.name, err)
+		}
+	}
+
+	restArgs := []string{}
+	if restIndex < len(l.Args) {
+		restArgs = l.Args[restIndex:]
+	}
+	if restField == nil {
+		if len(restArgs) != 0 {
+			return fmt.Errorf("%w %d: %q", ErrUnexpectedArgument, restIndex, restArgs[0])
+		}
+		return nil
+	}
+	if restField.value.Kind() != reflect.Slice {
+		return fmt.Errorf("field %s tagged with %s must be a slice", restField.name, argsTagOption)
+	}
+	// Without leftover arguments the field keeps any values set from its path.
+	if len(restArgs) == 0 {
+		return nil
+	}
+	restValues := reflect.MakeSlice(restField.value.Type(), len(restArgs), len(restArgs))
+	for i, restArg := range restArgs {
+		if err := setArgValue(restValues.Index(i), restArg); err != nil {
+			return fmt.Errorf("%w %d for %s: %w", ErrInvalidArgument, restIndex+i, restField.name, err)
+		}
+	}
+	restField.value.Set(restValues)
+	return nil
+}
+
+func collectPositionalFields(target reflect.Value, positionalFields *[]positionalField) error {
+	switch target.Kind() {
+	case reflect.Ptr:
+		if target.IsNil() {
+			return nil
+		}
+		return collectPositionalFields(target.Elem(), positionalFields)
+
+	case reflect.Struct:
+		typ := target.Type()
+		for i := 0; i < target.NumField(); i++ {
+			field := target.Field(i)
+			structField := typ.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+
+			tag, err := parseFieldTag(structField)
+			if err != nil {
+				return err
+			}
+			if tag.isArg || tag.isArgs {
+				*positionalFields = append(*positionalFields, positionalField{name: structField.Name, tag: tag, value: field})
+				continue
+			}
+			if err := collectPositionalFields(field, positionalFields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setArgValue sets target from a positional argument. Unlike values from
+// other sources, an argument which can't be converted to the target's type is
+// an error.
+func setArgValue(target reflect.Value, value string) error {
+	switch target.Kind() {
+	case reflect.Ptr:
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return setArgValue(target.Elem(), value)
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intValue, err := strconv.ParseInt(value, 10, target.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", value, target.Kind())
+		}
+		target.SetInt(intValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintValue, err := strconv.ParseUint(value, 10, target.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", value, target.Kind())
+		}
+		target.SetUint(uintValue)
+	case reflect.Float32, reflect.Float64:
+		floatValue, err := strconv.ParseFloat(value, target.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", value, target.Kind())
+		}
+		target.SetFloat(floatValue)
+	case reflect.Bool:
+		boolValue, ok := intoBool(value)
+		if !ok {
+			return fmt.Errorf("%q is not a valid bool", value)
+		}
+		target.SetBool(boolValue)
+	default:
+		return fmt.Errorf("unsupported type %s", target.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package orale_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestPositionalArgs(t *testing.T) {
+	t.Parallel()
+
+	type TestConfig struct {
+		Verbose bool     `config:"verbose"`
+		Command string   `config:",arg=0"`
+		Dir     string   `config:"dir,arg=1"`
+		Port    *int     `config:",arg=2,optional"`
+		Rest    []string `config:",args"`
+	}
+
+	load := func(t *testing.T, args []string, target any) *orale.Loader {
+		conf, err := orale.Load("app",
+			orale.WithArgs(args),
+			orale.WithEnviron([]string{}),
+			orale.WithWorkingDir(t.TempDir()),
+			orale.WithTarget(target),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conf
+	}
+
+	t.Run("should bind positional arguments to tagged fields", func(t *testing.T) {
+		t.Parallel()
+
+		testConf := TestConfig{}
+		conf := load(t, []string{"--verbose", "serve", "./data", "8080", "a", "--", "--b"}, &testConf)
+		if err := conf.GetAll(&testConf); err != nil {
+			t.Fatal(err)
+		}
+
+		if !testConf.Verbose {
+			t.Fatal("expected Verbose to be true")
+		}
+		if testConf.Command != "serve" {
+			t.Fatalf("expected Command to be serve, got %s", testConf.Command)
+		}
+		if testConf.Dir != "./data" {
+			t.Fatalf("expected Dir to be ./data, got %s", testConf.Dir)
+		}
+		if testConf.Port == nil || *testConf.Port != 8080 {
+			t.Fatalf("expected Port to be 8080, got %v", testConf.Port)
+		}
+		if len(testConf.Rest) != 2 || testConf.Rest[0] != "a" || testConf.Rest[1] != "--b" {
+			t.Fatalf("expected Rest to be [a --b], got %q", testConf.Rest)
+		}
+	})
+
+	t.Run("should allow optional arguments to be omitted", func(t *testing.T) {
+		t.Parallel()
+
+		testConf := TestConfig{}
+		conf := load(t, []string{"serve", "./data"}, &testConf)
+		if err := conf.GetAll(&testConf); err != nil {
+			t.Fatal(err)
+		}
+
+		if testConf.Port == nil || *testConf.Port != 0 {
+			t.Fatalf("expected Port to be left at 0, got %v", testConf.Port)
+		}
+		if len(testConf.Rest) != 0 {
+			t.Fatalf("expected Rest to be empty, got %q", testConf.Rest)
+		}
+	})
+
+	t.Run("should keep values from other sources when no arguments are left over", func(t *testing.T) {
+		t.Parallel()
+
+		type FilesConfig struct {
+			Files []string `config:"files,args"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{"app.config.toml": {Data: []byte(`files = ["a", "b"]`)}}),
+			orale.WithTarget(&FilesConfig{}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := FilesConfig{}
+		if err := conf.Get("", &testConf); err != nil {
+			t.Fatal(err)
+		}
+		if len(testConf.Files) != 2 || testConf.Files[0] != "a" || testConf.Files[1] != "b" {
+			t.Fatalf("expected Files to be [a b] from the file, got %q", testConf.Files)
+		}
+	})
+
+	t.Run("should report arity and type errors through Get", func(t *testing.T) {
+		t.Parallel()
+
+		type ExactConfig struct {
+			Name  string `config:",arg=0"`
+			Count uint8  `config:",arg=1"`
+		}
+
+		cases := []struct {
+			args []string
+			err  error
+		}{
+			{[]string{"name"}, orale.ErrMissingArgument},
+			{[]string{"name", "1", "extra"}, orale.ErrUnexpectedArgument},
+			{[]string{"name", "many"}, orale.ErrInvalidArgument},
+			{[]string{"name", "256"}, orale.ErrInvalidArgument},
+		}
+
+		for _, c := range cases {
+			testConf := ExactConfig{}
+			conf := load(t, c.args, &testConf)
+			if err := conf.GetAll(&testConf); !errors.Is(err, c.err) {
+				t.Fatalf("expected %v for %q, got %v", c.err, c.args, err)
+			}
+		}
+	})
+
+	t.Run("should ignore positional arguments when the target has no positional fields", func(t *testing.T) {
+		t.Parallel()
+
+		type PlainConfig struct {
+			Verbose bool `config:"verbose"`
+		}
+
+		testConf := PlainConfig{}
+		conf := load(t, []string{"serve", "--verbose"}, &testConf)
+		if err := conf.GetAll(&testConf); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.Args) != 1 || conf.Args[0] != "serve" {
+			t.Fatalf("expected Args to be [serve], got %q", conf.Args)
+		}
+	})
+
+	t.Run("should return an error for invalid tags", func(t *testing.T) {
+		t.Parallel()
+
+		type InvalidConfig struct {
+			Name string `config:",arg=first"`
+		}
+
+		testConf := InvalidConfig{}
+		conf := load(t, []string{}, &testConf)
+		if err := conf.GetAll(&testConf); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
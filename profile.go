@@ -0,0 +1,75 @@
+package orale
+
+import (
+	"fmt"
+	"strings"
+)
+
+// profilesKey is the top level key of a configuration file holding profiles.
+const profilesKey = "profiles"
+
+// applyProfiles overlays the profiles within a configuration file's values
+// which match the given environment names onto the file's top level values.
+// Profiles are applied in the order of environmentNames so later environments
+// take precedence. All profiles are then removed so they don't appear as
+// configuration paths. Profiles may be nested tables, as in toml, yaml, and
+// json, or dotted keys, as in ini and properties. Since the key is reserved, a
+// profiles table holding anything other than tables is an error rather than
+// being dropped.
+func applyProfiles(hierarchicalFileValues map[string]any, environmentNames []string) error {
+	profiles := map[string]any{}
+	if value, ok := hierarchicalFileValues[profilesKey]; ok {
+		profileValues, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is reserved for profiles and must be a table", profilesKey)
+		}
+		for profileName, profile := range profileValues {
+			if _, ok := profile.(map[string]any); !ok {
+				return fmt.Errorf("%s is reserved for profiles and must only contain tables, but %s.%s is not a table", profilesKey, profilesKey, profileName)
+			}
+		}
+		profiles = profileValues
+		delete(hierarchicalFileValues, profilesKey)
+	}
+
+	dottedProfiles := map[string]map[string]any{}
+	for key, value := range hierarchicalFileValues {
+		if !strings.HasPrefix(key, profilesKey+".") {
+			continue
+		}
+		delete(hierarchicalFileValues, key)
+		profileName, profileKey, ok := strings.Cut(strings.TrimPrefix(key, profilesKey+"."), ".")
+		if !ok {
+			return fmt.Errorf("%s is reserved for profiles and must only contain tables, but %s is not a table", profilesKey, key)
+		}
+		if dottedProfiles[profileName] == nil {
+			dottedProfiles[profileName] = map[string]any{}
+		}
+		dottedProfiles[profileName][profileKey] = value
+	}
+
+	for _, environmentName := range environmentNames {
+		if profile, ok := profiles[environmentName]; ok {
+			mergeValues(hierarchicalFileValues, profile.(map[string]any))
+		}
+		for key, value := range dottedProfiles[environmentName] {
+			hierarchicalFileValues[key] = value
+		}
+	}
+
+	return nil
+}
+
+// mergeValues deeply merges overlay into base. Tables are merged key by key,
+// while any other value, including lists, replaces the value in base.
+func mergeValues(base map[string]any, overlay map[string]any) {
+	for key, overlayValue := range overlay {
+		overlayTable, overlayIsTable := overlayValue.(map[string]any)
+		baseTable, baseIsTable := base[key].(map[string]any)
+		if overlayIsTable && baseIsTable {
+			mergeValues(baseTable, overlayTable)
+			continue
+		}
+		base[key] = overlayValue
+	}
+}
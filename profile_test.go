@@ -0,0 +1,157 @@
+package orale_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestProfiles(t *testing.T) {
+	t.Parallel()
+
+	fileSystem := fstest.MapFS{
+		"app.config.toml": {Data: []byte(`
+name = "app"
+hosts = ["a", "b", "c"]
+
+[db]
+host = "localhost"
+port = 5432
+
+[profiles.production.db]
+host = "db.example.com"
+
+[profiles.production]
+hosts = ["prod"]
+
+[profiles.eu.db]
+port = 6432
+`)},
+		"legacy/app.config.ini": {Data: []byte(`
+name = legacy
+
+[db]
+host = localhost
+
+[profiles.production.db]
+host = db.example.com
+`)},
+	}
+
+	type TestConfig struct {
+		Name  string   `config:"name"`
+		Hosts []string `config:"hosts"`
+		Db    struct {
+			Host string `config:"host"`
+			Port int    `config:"port"`
+		} `config:"db"`
+	}
+
+	t.Run("should overlay the selected profiles onto the top level values", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-environment=production,eu"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for path := range conf.ConfigurationFiles()[0].Values {
+			if len(path) >= len("profiles") && path[:len("profiles")] == "profiles" {
+				t.Fatalf("expected no profile paths, got %s", path)
+			}
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Name != "app" {
+			t.Fatalf("expected Name to be app, got %s", testConf.Name)
+		}
+		if testConf.Db.Host != "db.example.com" {
+			t.Fatalf("expected Db.Host to be db.example.com, got %s", testConf.Db.Host)
+		}
+		if testConf.Db.Port != 6432 {
+			t.Fatalf("expected Db.Port to be 6432, got %d", testConf.Db.Port)
+		}
+		if len(testConf.Hosts) != 1 || testConf.Hosts[0] != "prod" {
+			t.Fatalf("expected Hosts to be [prod], got %v", testConf.Hosts)
+		}
+	})
+
+	t.Run("should ignore profiles when no environment is selected", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(conf.ConfigurationFiles()[0].Values) != 6 {
+			t.Fatalf("expected 6 paths, got %v", conf.ConfigurationFiles()[0].Values)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Db.Host != "localhost" {
+			t.Fatalf("expected Db.Host to be localhost, got %s", testConf.Db.Host)
+		}
+		if len(testConf.Hosts) != 3 {
+			t.Fatalf("expected Hosts to have 3 values, got %v", testConf.Hosts)
+		}
+	})
+
+	t.Run("should apply profiles written as dotted keys", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-environment=production"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("legacy"),
+			orale.WithMaxSearchDepth(0),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Db.Host != "db.example.com" {
+			t.Fatalf("expected Db.Host to be db.example.com, got %s", testConf.Db.Host)
+		}
+		if _, ok := conf.ConfigurationFiles()[0].Values["profiles.production.db.host"]; ok {
+			t.Fatal("expected no profile paths")
+		}
+	})
+
+	t.Run("should return an error when profiles holds values other than tables", func(t *testing.T) {
+		t.Parallel()
+
+		for _, fileSystem := range []fstest.MapFS{
+			{"app.config.toml": {Data: []byte("[profiles]\nadmin = \"bob\"")}},
+			{"app.config.ini": {Data: []byte("[profiles]\nadmin = bob")}},
+			{"app.config.toml": {Data: []byte(`profiles = "bob"`)}},
+		} {
+			_, err := orale.Load("app",
+				orale.WithArgs([]string{}),
+				orale.WithEnviron([]string{}),
+				orale.WithFS(fileSystem),
+			)
+			if err == nil || !strings.Contains(err.Error(), "reserved for profiles") {
+				t.Fatalf("expected a reserved profiles error, got %v", err)
+			}
+		}
+	})
+}
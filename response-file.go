@@ -0,0 +1,104 @@
+package orale
+
+import (
+	"fmt"
+	"strings"
+)
+
+const responseFilePrefix = "@"
+const responseFileCommentPrefix = "#"
+
+// ArgumentOrigin describes where a program argument came from.
+type ArgumentOrigin struct {
+	// Index is the position of the argument within the program arguments. For
+	// arguments read from a response file it is the position of the `@file`
+	// argument which led to the file being read.
+	Index int
+	// File is the path of the response file the argument was read from. It is
+	// empty for arguments given directly.
+	File string
+	// Line is the line of File the argument was read from.
+	Line int
+}
+
+// argument is a program argument along with its origin. responseFiles lists
+// the response files followed to reach the argument, outermost first.
+type argument struct {
+	value         string
+	origin        ArgumentOrigin
+	responseFiles []string
+}
+
+func plainArguments(programArgs []string) []argument {
+	args := []argument{}
+	for i, programArg := range programArgs {
+		args = append(args, argument{value: programArg, origin: ArgumentOrigin{Index: i}})
+	}
+	return args
+}
+
+// responseFileReader reads response files named by `@path` arguments. Response
+// files contain one argument per line. Surrounding whitespace is trimmed, and
+// blank lines and lines starting with `#` are skipped. A line wrapped in single
+// or double quotes is unquoted, which allows an argument to keep surrounding
+// whitespace or start with `#`. Response files may refer to other response
+// files, with relative paths resolved against the directory of the file
+// containing them. Paths given directly are resolved against workingDir.
+//
+// The flag parser reads a response file when it comes across a `@path`
+// argument in place of a flag or positional argument, so an argument taken as
+// the value of the flag before it, or given after `--`, is left alone.
+type responseFileReader struct {
+	fileSystem fileSystem
+	workingDir string
+}
+
+func isResponseFileArg(arg string) bool {
+	return strings.HasPrefix(arg, responseFilePrefix) && arg != responseFilePrefix
+}
+
+// read returns the arguments listed in the response file named by arg.
+func (r *responseFileReader) read(arg argument) ([]argument, error) {
+	baseDir := r.workingDir
+	if arg.origin.File != "" {
+		baseDir = r.fileSystem.dir(arg.origin.File)
+	}
+	responseFilePath := r.fileSystem.resolve(baseDir, strings.TrimPrefix(arg.value, responseFilePrefix))
+	for _, stackPath := range arg.responseFiles {
+		if stackPath == responseFilePath {
+			return nil, responseFileError(arg, fmt.Errorf("%w %s: cycle through %s", ErrResponseFile, responseFilePath, strings.Join(append(arg.responseFiles, responseFilePath), " -> ")))
+		}
+	}
+
+	fileBytes, err := r.fileSystem.readFile(responseFilePath)
+	if err != nil {
+		return nil, responseFileError(arg, fmt.Errorf("%w %s: %w", ErrResponseFile, responseFilePath, err))
+	}
+
+	responseFiles := append(append([]string{}, arg.responseFiles...), responseFilePath)
+	fileArgs := []argument{}
+	for i, line := range strings.Split(string(fileBytes), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, responseFileCommentPrefix) {
+			continue
+		}
+		fileArg := argument{
+			value:         line,
+			origin:        ArgumentOrigin{Index: arg.origin.Index, File: responseFilePath, Line: i + 1},
+			responseFiles: responseFiles,
+		}
+		if strings.HasPrefix(line, `"`) || strings.HasPrefix(line, "'") {
+			unquotedLine, err := unquoteFlagValue(line)
+			if err != nil {
+				return nil, responseFileError(fileArg, err)
+			}
+			fileArg.value = unquotedLine
+		}
+		fileArgs = append(fileArgs, fileArg)
+	}
+	return fileArgs, nil
+}
+
+func responseFileError(arg argument, err error) error {
+	return &FlagError{Arg: arg.value, Index: arg.origin.Index, File: arg.origin.File, Line: arg.origin.Line, Err: err}
+}
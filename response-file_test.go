@@ -0,0 +1,163 @@
+package orale_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestResponseFiles(t *testing.T) {
+	t.Parallel()
+
+	fileSystem := fstest.MapFS{
+		"project/args.txt": {Data: []byte(`# Generated flags
+--name=app
+
+  --tags=a
+"--tags=  padded  "
+@nested/more.txt
+`)},
+		"project/nested/more.txt": {Data: []byte("--port\n8080\n'# not a comment'\n")},
+		"project/cycle-a.txt":     {Data: []byte("@cycle-b.txt")},
+		"project/cycle-b.txt":     {Data: []byte("--a=1\n@cycle-a.txt")},
+		"project/bad.txt":         {Data: []byte("--ok=1\n--=bad")},
+	}
+
+	t.Run("should expand response files recursively with provenance", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Name    string   `config:"name"`
+			Tags    []string `config:"tags"`
+			Port    int      `config:"port"`
+			Verbose bool     `config:"verbose"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--verbose", "@args.txt", "--tags=b", "--", "@ignored.txt"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+			orale.WithTarget(&TestConfig{}),
+			orale.WithResponseFiles(true),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Name != "app" {
+			t.Fatalf("expected Name to be app, got %s", testConf.Name)
+		}
+		if len(testConf.Tags) != 3 || testConf.Tags[1] != "  padded  " || testConf.Tags[2] != "b" {
+			t.Fatalf("expected Tags to be [a, '  padded  ', b], got %q", testConf.Tags)
+		}
+		if testConf.Port != 8080 {
+			t.Fatalf("expected Port to be 8080, got %d", testConf.Port)
+		}
+
+		nameOrigin := conf.FlagOrigins["name"][0]
+		if nameOrigin.File != "project/args.txt" || nameOrigin.Line != 2 || nameOrigin.Index != 1 {
+			t.Fatalf("expected name to come from project/args.txt:2, got %+v", nameOrigin)
+		}
+		portOrigin := conf.FlagOrigins["port"][0]
+		if portOrigin.File != "project/nested/more.txt" || portOrigin.Line != 2 {
+			t.Fatalf("expected port to come from project/nested/more.txt:2, got %+v", portOrigin)
+		}
+		verboseOrigin := conf.FlagOrigins["verbose"][0]
+		if verboseOrigin.File != "" || verboseOrigin.Index != 0 {
+			t.Fatalf("expected verbose to come from the command line, got %+v", verboseOrigin)
+		}
+		if _, ok := conf.FlagValues()["#NotAComment"]; ok {
+			t.Fatal("expected quoted lines not to be parsed as flags")
+		}
+	})
+
+	t.Run("should report cycles, missing files, and errors within response files", func(t *testing.T) {
+		t.Parallel()
+
+		cases := []struct {
+			args []string
+			file string
+			line int
+			err  error
+		}{
+			{[]string{"@cycle-a.txt"}, "project/cycle-b.txt", 2, orale.ErrResponseFile},
+			{[]string{"@missing.txt"}, "", 0, orale.ErrResponseFile},
+			{[]string{"@bad.txt"}, "project/bad.txt", 2, orale.ErrInvalidFlagName},
+		}
+
+		for _, c := range cases {
+			_, err := orale.Load("app",
+				orale.WithArgs(c.args),
+				orale.WithEnviron([]string{}),
+				orale.WithFS(fileSystem),
+				orale.WithWorkingDir("project"),
+				orale.WithResponseFiles(true),
+			)
+
+			var flagErr *orale.FlagError
+			if !errors.As(err, &flagErr) || !errors.Is(err, c.err) {
+				t.Fatalf("expected %v for %q, got %v", c.err, c.args, err)
+			}
+			if flagErr.File != c.file || flagErr.Line != c.line {
+				t.Fatalf("expected error at %s:%d for %q, got %s:%d", c.file, c.line, c.args, flagErr.File, flagErr.Line)
+			}
+		}
+	})
+
+	t.Run("should leave @ arguments alone when disabled", func(t *testing.T) {
+		t.Parallel()
+
+		for _, opts := range [][]orale.LoadOption{{}, {orale.WithResponseFiles(false)}} {
+			conf, err := orale.Load("app", append([]orale.LoadOption{
+				orale.WithArgs([]string{"--user", "@someone", "@args.txt"}),
+				orale.WithEnviron([]string{}),
+				orale.WithFS(fileSystem),
+				orale.WithWorkingDir("project"),
+			}, opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if conf.FlagValues()["user"][0] != "@someone" {
+				t.Fatalf("expected user to be @someone, got %v", conf.FlagValues()["user"])
+			}
+			if len(conf.Args) != 1 || conf.Args[0] != "@args.txt" {
+				t.Fatalf("expected @args.txt to be positional, got %v", conf.Args)
+			}
+		}
+	})
+
+	t.Run("should not expand an argument taken as a flag's value", func(t *testing.T) {
+		t.Parallel()
+
+		type TestConfig struct {
+			Mention string `config:"mention"`
+			Name    string `config:"name"`
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--mention", "@bob", "@args.txt"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithWorkingDir("project"),
+			orale.WithTarget(&TestConfig{}),
+			orale.WithResponseFiles(true),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.FlagValues()["mention"][0] != "@bob" {
+			t.Fatalf("expected mention to be @bob, got %v", conf.FlagValues()["mention"])
+		}
+		if conf.FlagValues()["name"][0] != "app" {
+			t.Fatalf("expected name to be app from args.txt, got %v", conf.FlagValues()["name"])
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package orale
+
+// Reasons the search for configuration files in the working directory and its
+// parents stopped. See SearchBoundary.
+const (
+	SearchStoppedAtRoot       = "root"
+	SearchStoppedAtMarker     = "marker"
+	SearchStoppedAtMaxDepth   = "maxDepth"
+	SearchStoppedAtFirstMatch = "firstMatch"
+)
+
+// SearchBoundary describes where and why the search for configuration files in
+// the working directory and its parents stopped.
+type SearchBoundary struct {
+	// Path is the last directory searched.
+	Path string
+	// Reason is why the search stopped. It is one of the SearchStoppedAt
+	// constants.
+	Reason string
+	// Marker is the name of the marker found in Path when Reason is
+	// SearchStoppedAtMarker.
+	Marker string
+}
+
+// searchLocation is a directory searched for configuration files along with
+// the configuration names to look for within it.
+type searchLocation struct {
+	dir         string
+	configNames []string
+}
+
+// ancestorSearchPaths returns the working directory followed by its parent
+// directories up to the first directory containing a boundary marker, or until
+// the maximum search depth is reached.
+func ancestorSearchPaths(fileSystem fileSystem, options *loadOptions) ([]string, SearchBoundary) {
+	ancestorPaths := []string{}
+	allAncestorPaths := fileSystem.searchPaths(options.workingDir)
+	for depth, ancestorPath := range allAncestorPaths {
+		ancestorPaths = append(ancestorPaths, ancestorPath)
+		for _, marker := range options.boundaryMarkers {
+			if fileSystem.exists(fileSystem.join(ancestorPath, marker)) {
+				return ancestorPaths, SearchBoundary{Path: ancestorPath, Reason: SearchStoppedAtMarker, Marker: marker}
+			}
+		}
+		if options.limitSearchDepth && depth >= options.maxSearchDepth {
+			return ancestorPaths, SearchBoundary{Path: ancestorPath, Reason: SearchStoppedAtMaxDepth}
+		}
+	}
+	return ancestorPaths, SearchBoundary{Path: ancestorPaths[len(ancestorPaths)-1], Reason: SearchStoppedAtRoot}
+}
+
+// stopAtFirstMatch trims ancestorPaths after the first directory containing a
+// configuration file.
+func stopAtFirstMatch(fileSystem fileSystem, options *loadOptions, templates fileNameTemplates, ancestorPaths []string, searchBoundary SearchBoundary) ([]string, SearchBoundary) {
+	for i, ancestorPath := range ancestorPaths {
+		for _, configName := range options.fileNames {
+			for _, configLayerName := range templates.layerNames(configName) {
+				found := false
+				for _, extension := range fileFormatExtensions() {
+					if fileSystem.exists(fileSystem.join(ancestorPath, configLayerName+"."+extension)) {
+						found = true
+					}
+				}
+				if dropInFilePaths, _ := dropInConfigFilePaths(fileSystem, fileSystem.join(ancestorPath, configLayerName+".d")); len(dropInFilePaths) != 0 {
+					found = true
+				}
+				if found {
+					return ancestorPaths[:i+1], SearchBoundary{Path: ancestorPath, Reason: SearchStoppedAtFirstMatch}
+				}
+			}
+		}
+	}
+	return ancestorPaths, searchBoundary
+}
+
+// configSearchLocations returns the directories to search for configuration
+// files in order of precedence. The working directory and its parents come
+// first, followed by the user and system locations when they are enabled.
+func configSearchLocations(fileSystem fileSystem, options *loadOptions, ancestorPaths []string) []searchLocation {
+	locations := []searchLocation{}
+	for _, ancestorPath := range ancestorPaths {
+		locations = append(locations, searchLocation{dir: ancestorPath, configNames: options.fileNames})
+	}
+
+	if options.userConfig {
+		environ := environMap(options.environ)
+		homeDir := environ["HOME"]
+		if homeDir == "" {
+			homeDir = environ["USERPROFILE"]
+		}
+
+		userConfigDirs := []string{}
+		if xdgConfigHome := environ["XDG_CONFIG_HOME"]; xdgConfigHome != "" {
+			userConfigDirs = append(userConfigDirs, fileSystem.join(xdgConfigHome))
+		}
+		if homeDir != "" {
+			userConfigDir := fileSystem.join(homeDir, ".config")
+			if len(userConfigDirs) == 0 || userConfigDirs[0] != userConfigDir {
+				userConfigDirs = append(userConfigDirs, userConfigDir)
+			}
+		}
+		for _, userConfigDir := range userConfigDirs {
+			for _, configName := range options.fileNames {
+				locations = append(locations, searchLocation{
+					dir:         fileSystem.join(userConfigDir, configName),
+					configNames: []string{configName},
+				})
+			}
+		}
+
+		if homeDir != "" {
+			dotConfigNames := []string{}
+			for _, configName := range options.fileNames {
+				dotConfigNames = append(dotConfigNames, "."+configName)
+			}
+			locations = append(locations, searchLocation{dir: fileSystem.join(homeDir), configNames: dotConfigNames})
+		}
+	}
+
+	if options.systemConfig {
+		for _, configName := range options.fileNames {
+			locations = append(locations, searchLocation{
+				dir:         fileSystem.join(systemConfigDir, configName),
+				configNames: []string{configName},
+			})
+		}
+	}
+
+	return locations
+}
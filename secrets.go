@@ -0,0 +1,76 @@
+package orale
+
+import "strings"
+
+// loadSecretsDirs reads each of the given directories as a tree of files, one
+// file per value, such as the directories used for Docker secrets, systemd
+// credentials, and Kubernetes secret volumes. File names are mapped into paths
+// the same way as environment variable names without their prefix, and
+// subdirectories separate path segments like double underscores. The contents
+// of each file, with surrounding whitespace trimmed, become its value. Values
+// in earlier directories take precedence over values in later ones. Missing
+// directories are ignored.
+func loadSecretsDirs(fileSystem fileSystem, dirs []string) (map[string][]any, error) {
+	secretValues := map[string][]any{}
+	for _, dir := range dirs {
+		if err := loadSecretsDir(fileSystem, dir, []string{}, secretValues); err != nil {
+			return nil, err
+		}
+	}
+	return secretValues, nil
+}
+
+// loadSecretsDir adds the files within dir to secretValues, skipping any paths
+// already present. Entries starting with a period are skipped, which excludes
+// hidden files as well as the `..data` links Kubernetes maintains within
+// secret volumes.
+func loadSecretsDir(fileSystem fileSystem, dir string, keyChunks []string, secretValues map[string][]any) error {
+	entries, err := fileSystem.readDir(dir)
+	if err != nil {
+		if isMissingFileErr(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		entryPath := fileSystem.join(dir, entry.Name())
+		entryKeyChunks := append(append([]string{}, keyChunks...), entry.Name())
+
+		// Entries may be symbolic links, so they are followed to find out
+		// whether they lead to a directory.
+		info, err := fileSystem.stat(entryPath)
+		if err != nil {
+			if isMissingFileErr(err) {
+				continue
+			}
+			return err
+		}
+		if info.IsDir() {
+			if err := loadSecretsDir(fileSystem, entryPath, entryKeyChunks, secretValues); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := environmentKeyToPath(strings.Join(entryKeyChunks, "__"))
+		if _, ok := secretValues[key]; ok {
+			continue
+		}
+
+		fileBytes, err := fileSystem.readFile(entryPath)
+		if err != nil {
+			if isMissingFileErr(err) {
+				continue
+			}
+			return err
+		}
+		secretValues[key] = []any{strings.TrimSpace(string(fileBytes))}
+	}
+
+	return nil
+}
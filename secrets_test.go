@@ -0,0 +1,97 @@
+package orale_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestSecretsDir(t *testing.T) {
+	t.Parallel()
+
+	fileSystem := fstest.MapFS{
+		"run/secrets/DB__PASSWORD":        {Data: []byte("hunter2\n")},
+		"run/secrets/api/token":           {Data: []byte("  abc123  ")},
+		"run/secrets/shared":              {Data: []byte("secrets")},
+		"run/secrets/.hidden":             {Data: []byte("hidden")},
+		"run/secrets/..data/DB__PASSWORD": {Data: []byte("stale")},
+		"run/credentials/shared":          {Data: []byte("credentials")},
+		"run/credentials/api-key":         {Data: []byte("key")},
+	}
+
+	type TestConfig struct {
+		Db struct {
+			Password string `config:"password"`
+		} `config:"db"`
+		Api struct {
+			Token string `config:"token"`
+		} `config:"api"`
+		ApiKey     string `config:"apiKey"`
+		Shared     string `config:"shared"`
+		Overridden string `config:"overridden"`
+	}
+
+	t.Run("should load values from secrets directories", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+			orale.WithSecretsDir("run/secrets", "run/credentials", "run/missing"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Db.Password != "hunter2" {
+			t.Errorf("expected db.password to be hunter2, got %q", testConf.Db.Password)
+		}
+		if testConf.Api.Token != "abc123" {
+			t.Errorf("expected api.token to be abc123, got %q", testConf.Api.Token)
+		}
+		if testConf.ApiKey != "key" {
+			t.Errorf("expected apiKey to be key, got %q", testConf.ApiKey)
+		}
+		if testConf.Shared != "secrets" {
+			t.Errorf("expected earlier directories to take precedence, got %q", testConf.Shared)
+		}
+		if len(conf.SecretValues()) != 4 {
+			t.Errorf("expected hidden entries to be skipped, got %v", conf.SecretValues())
+		}
+	})
+
+	t.Run("should take precedence over dotenv files but not environment variables", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"secrets/overridden": {Data: []byte("secrets")},
+			"secrets/shared":     {Data: []byte("secrets")},
+			".env":               {Data: []byte("APP__SHARED=dotenv\nAPP__OVERRIDDEN=dotenv")},
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{"APP__OVERRIDDEN=environment"}),
+			orale.WithFS(fileSystem),
+			orale.WithSecretsDir("secrets"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.Shared != "secrets" {
+			t.Errorf("expected secrets to override dotenv files, got %q", testConf.Shared)
+		}
+		if testConf.Overridden != "environment" {
+			t.Errorf("expected environment variables to override secrets, got %q", testConf.Overridden)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package orale
+
+import "sort"
+
+// Default priorities of the built in sources. Sources with a higher priority
+// take precedence over sources with a lower priority.
+const (
+	DefaultsPriority    = -100
+	FilePriority        = 0
+	DotenvPriority      = 50
+	SecretsPriority     = 75
+	EnvironmentPriority = 100
+	FlagPriority        = 200
+)
+
+// SourceKind identifies one of the built in kinds of source. It is used to
+// configure the precedence of the built in sources with WithPrecedence and
+// WithPriority.
+type SourceKind string
+
+// The built in kinds of source.
+const (
+	Flags       SourceKind = "flags"
+	Environment SourceKind = "environment"
+	Secrets     SourceKind = "secrets"
+	Dotenv      SourceKind = "dotenv"
+	Files       SourceKind = "files"
+	Defaults    SourceKind = "defaults"
+)
+
+// defaultPrecedence lists the built in kinds of source from highest to lowest
+// priority.
+var defaultPrecedence = []SourceKind{Flags, Environment, Secrets, Dotenv, Files, Defaults}
+
+var defaultPriorities = map[SourceKind]int{
+	Flags:       FlagPriority,
+	Environment: EnvironmentPriority,
+	Secrets:     SecretsPriority,
+	Dotenv:      DotenvPriority,
+	Files:       FilePriority,
+	Defaults:    DefaultsPriority,
+}
+
+// Names of the built in map backed sources.
+const (
+	FlagSourceName        = "flags"
+	EnvironmentSourceName = "environment"
+	SecretsSourceName     = "secrets"
+	DotenvSourceName      = "dotenv"
+)
+
+// Source is a provider of configuration values. Orale ships with sources for
+// flags, environment variables, and configuration files, but any type
+// implementing this interface can be added to a Loader.
+type Source interface {
+	// Name identifies the source. For files this is the path of the file.
+	Name() string
+	// Priority determines the precedence of the source. When more than one
+	// source has a value for a path, the value from the source with the highest
+	// priority is used. Sources with equal priority are consulted in the order
+	// they appear in the Loader.
+	Priority() int
+	// FlatValues returns the values of the source flattened into paths
+	// separated by periods, with slice indexes in square brackets. See
+	// File.Values for details.
+	FlatValues() map[string][]any
+}
+
+// MapSource is a Source backed by a map of flattened values. Flags and
+// environment variables are loaded into MapSources, and it is a convenient way
+// to supply values from a custom provider.
+type MapSource struct {
+	name     string
+	priority int
+	values   map[string][]any
+}
+
+var _ Source = &MapSource{}
+
+// NewMapSource creates a MapSource with the given name, priority, and
+// flattened values.
+func NewMapSource(name string, priority int, values map[string][]any) *MapSource {
+	if values == nil {
+		values = map[string][]any{}
+	}
+	return &MapSource{
+		name:     name,
+		priority: priority,
+		values:   values,
+	}
+}
+
+// Name returns the name of the source.
+func (s *MapSource) Name() string {
+	return s.name
+}
+
+// Priority returns the priority of the source.
+func (s *MapSource) Priority() int {
+	return s.priority
+}
+
+// FlatValues returns the flattened values of the source.
+func (s *MapSource) FlatValues() map[string][]any {
+	return s.values
+}
+
+// resolvePriorities returns the priority of each kind of source. The kinds in
+// precedence take the default priority slots from highest to lowest, followed
+// by any kinds not listed in their default order. Priorities given explicitly
+// override the result.
+func resolvePriorities(precedence []SourceKind, priorities map[SourceKind]int) map[SourceKind]int {
+	orderedKinds := []SourceKind{}
+	seenKinds := map[SourceKind]bool{}
+	for _, kind := range append(append([]SourceKind{}, precedence...), defaultPrecedence...) {
+		if _, ok := defaultPriorities[kind]; !ok || seenKinds[kind] {
+			continue
+		}
+		seenKinds[kind] = true
+		orderedKinds = append(orderedKinds, kind)
+	}
+
+	resolvedPriorities := map[SourceKind]int{}
+	for i, kind := range orderedKinds {
+		resolvedPriorities[kind] = defaultPriorities[defaultPrecedence[i]]
+	}
+	for kind, priority := range priorities {
+		resolvedPriorities[kind] = priority
+	}
+	return resolvedPriorities
+}
+
+func sortSources(sources []Source) []Source {
+	sortedSources := make([]Source, len(sources))
+	copy(sortedSources, sources)
+	sort.SliceStable(sortedSources, func(i, j int) bool {
+		return sortedSources[i].Priority() > sortedSources[j].Priority()
+	})
+	return sortedSources
+}
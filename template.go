@@ -0,0 +1,137 @@
+package orale
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"strings"
+)
+
+// DefaultFileNameTemplates are the templates used to name configuration files
+// when no others are given with WithFileNameTemplates. Templates listed later
+// take precedence over templates listed earlier.
+var DefaultFileNameTemplates = []string{
+	"{name}.config",
+	"{name}.{env}.config",
+	"{name}.config.local",
+	"{name}.{env}.config.local",
+}
+
+// fileNameTemplates expands configuration file name templates. Templates name
+// files without their extension, and may contain the following placeholders:
+//
+//   - {name}: the configuration name, such as `my-app`
+//   - {env}: a configuration environment. Templates containing {env} are
+//     expanded once for each environment, and are skipped when no environment
+//     is set
+//   - {hostname}: the name of the host
+//   - {user}: the name of the current user
+//   - {goos}: the operating system, as in runtime.GOOS
+//   - {goarch}: the architecture, as in runtime.GOARCH
+//
+// Templates with a placeholder that has no value are skipped.
+type fileNameTemplates struct {
+	templates        []string
+	environmentNames []string
+	values           map[string]string
+}
+
+var fileNameTemplatePlaceholders = []string{"name", "env", "hostname", "user", "goos", "goarch"}
+
+func newFileNameTemplates(templates []string, environmentNames []string, environ []string) (fileNameTemplates, error) {
+	if templates == nil {
+		templates = DefaultFileNameTemplates
+	}
+	for _, template := range templates {
+		if err := validateFileNameTemplate(template); err != nil {
+			return fileNameTemplates{}, err
+		}
+	}
+
+	values := map[string]string{
+		"goos":   runtime.GOOS,
+		"goarch": runtime.GOARCH,
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		values["hostname"] = hostname
+	}
+	environmentVariables := environMap(environ)
+	if userName := environmentVariables["USER"]; userName != "" {
+		values["user"] = userName
+	} else if userName := environmentVariables["USERNAME"]; userName != "" {
+		values["user"] = userName
+	} else if currentUser, err := user.Current(); err == nil {
+		values["user"] = currentUser.Username
+	}
+
+	return fileNameTemplates{
+		templates:        templates,
+		environmentNames: environmentNames,
+		values:           values,
+	}, nil
+}
+
+func validateFileNameTemplate(template string) error {
+	remaining := template
+	for {
+		startIndex := strings.IndexByte(remaining, '{')
+		if startIndex == -1 {
+			return nil
+		}
+		endIndex := strings.IndexByte(remaining[startIndex:], '}')
+		if endIndex == -1 {
+			return fmt.Errorf("unterminated placeholder in file name template %q", template)
+		}
+		placeholder := remaining[startIndex+1 : startIndex+endIndex]
+		known := false
+		for _, knownPlaceholder := range fileNameTemplatePlaceholders {
+			if placeholder == knownPlaceholder {
+				known = true
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown placeholder {%s} in file name template %q", placeholder, template)
+		}
+		remaining = remaining[startIndex+endIndex+1:]
+	}
+}
+
+// layerNames returns the names, without extension, of the files that may hold
+// configuration for configName in order of precedence.
+func (t fileNameTemplates) layerNames(configName string) []string {
+	layerNames := []string{}
+	for i := len(t.templates) - 1; i >= 0; i -= 1 {
+		template := t.templates[i]
+		if !strings.Contains(template, "{env}") {
+			if layerName, ok := t.expand(template, configName, ""); ok {
+				layerNames = append(layerNames, layerName)
+			}
+			continue
+		}
+		for j := len(t.environmentNames) - 1; j >= 0; j -= 1 {
+			if layerName, ok := t.expand(template, configName, t.environmentNames[j]); ok {
+				layerNames = append(layerNames, layerName)
+			}
+		}
+	}
+	return layerNames
+}
+
+func (t fileNameTemplates) expand(template string, configName string, environmentName string) (string, bool) {
+	values := map[string]string{"name": configName, "env": environmentName}
+	for key, value := range t.values {
+		values[key] = value
+	}
+	for _, placeholder := range fileNameTemplatePlaceholders {
+		token := "{" + placeholder + "}"
+		if !strings.Contains(template, token) {
+			continue
+		}
+		if values[placeholder] == "" {
+			return "", false
+		}
+		template = strings.ReplaceAll(template, token, values[placeholder])
+	}
+	return template, true
+}
@@ -0,0 +1,99 @@
+package orale_test
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestFileNameTemplates(t *testing.T) {
+	t.Parallel()
+
+	type TestConfig struct {
+		A string `config:"a"`
+		B string `config:"b"`
+		C string `config:"c"`
+		D string `config:"d"`
+		E string `config:"e"`
+	}
+
+	t.Run("should give local files precedence with the default templates", func(t *testing.T) {
+		t.Parallel()
+
+		fileSystem := fstest.MapFS{
+			"app.config.toml":            {Data: []byte("a = \"base\"\nb = \"base\"\nc = \"base\"\nd = \"base\"")},
+			"app.test.config.toml":       {Data: []byte("b = \"test\"\nc = \"test\"\nd = \"test\"")},
+			"app.config.local.yaml":      {Data: []byte("c: local\nd: local")},
+			"app.test.config.local.json": {Data: []byte(`{"d": "test-local"}`)},
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{"--config-environment=test"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fileSystem),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "base" || testConf.B != "test" || testConf.C != "local" || testConf.D != "test-local" {
+			t.Fatalf("expected base, test, local, test-local, got %+v", testConf)
+		}
+	})
+
+	t.Run("should expand host, user, and os placeholders", func(t *testing.T) {
+		t.Parallel()
+
+		hostname, err := os.Hostname()
+		if err != nil {
+			t.Skip("hostname unavailable")
+		}
+
+		fileSystem := fstest.MapFS{
+			"app.config.toml":                              {Data: []byte("a = \"base\"\nb = \"base\"\nc = \"base\"\nd = \"base\"")},
+			"app." + runtime.GOOS + ".config.toml":         {Data: []byte("b = \"goos\"\nc = \"goos\"\nd = \"goos\"")},
+			"app." + hostname + ".config.toml":             {Data: []byte("c = \"hostname\"\nd = \"hostname\"")},
+			"app.jane.config.toml":                         {Data: []byte("d = \"user\"")},
+			"app.config.local.toml":                        {Data: []byte("e = \"ignored\"")},
+			"app.missing-" + runtime.GOOS + ".config.toml": {Data: []byte("e = \"ignored\"")},
+		}
+
+		conf, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{"USER=jane"}),
+			orale.WithFS(fileSystem),
+			orale.WithFileNameTemplates("{name}.config", "{name}.{goos}.config", "{name}.{hostname}.config", "{name}.{user}.config"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testConf := TestConfig{}
+		conf.MustGet("", &testConf)
+
+		if testConf.A != "base" || testConf.B != "goos" || testConf.C != "hostname" || testConf.D != "user" || testConf.E != "" {
+			t.Fatalf("expected base, goos, hostname, user, and no e, got %+v", testConf)
+		}
+	})
+
+	t.Run("should return an error for unknown placeholders", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := orale.Load("app",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+			orale.WithFileNameTemplates("{name}.{region}.config"),
+		)
+		if err == nil || !strings.Contains(err.Error(), "{region}") {
+			t.Fatalf("expected an unknown placeholder error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,330 @@
+package orale
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+const usageIndent = "  "
+const usageDetailIndent = "      "
+
+// usageFlag describes a configuration path of the target in usage text.
+type usageFlag struct {
+	path         string
+	typeName     string
+	isBool       bool
+	defaultValue string
+	desc         string
+	values       []string
+}
+
+// usageArg describes a field of the target bound to positional arguments.
+type usageArg struct {
+	name         string
+	tag          fieldTag
+	defaultValue string
+	desc         string
+}
+
+// Usage returns usage text for the configuration read by Get with the same
+// path and target. Every path of the target is listed with its flag, its
+// environment variable, its key in configuration files, its type, its default,
+// and its description. Boolean flags are shown as `--[no-]flag` in place of a
+// type. The default is the target's current value, so Usage should be called
+// before Get. Descriptions are taken from the `desc` tag of each field, and the
+// values a field accepts may be listed in its `enum` tag:
+//
+//	type Config struct {
+//		Port     int    `config:"port" desc:"Port to listen on"`
+//		LogLevel string `config:"logLevel" desc:"Log level" enum:"debug,info,warn"`
+//	}
+//
+// Positional arguments and the subcommands of the selected command are listed
+// as well, when path is the selected command's path. Otherwise the top level
+// commands are listed. Flags of the selected command are shown without the command's path
+// when they can be given that way.
+func (l *Loader) Usage(path string, target any) string {
+	flags := []usageFlag{}
+	args := []usageArg{}
+	collectUsage(path, reflect.ValueOf(target), reflect.StructField{}, &flags, &args, map[reflect.Type]bool{})
+
+	// The selected command only applies when describing its own target, so
+	// usage for the global target lists the top level commands.
+	commandNames := []string{}
+	if path == l.CommandPath() {
+		commandNames = l.Command
+	}
+	commands := l.commands
+	for _, commandName := range commandNames {
+		if command := findCommand(commands, commandName); command != nil {
+			commands = command.Commands
+		}
+	}
+
+	usage := &strings.Builder{}
+
+	usageLine := append([]string{"Usage:"}, l.name)
+	usageLine = append(usageLine, commandNames...)
+	if len(commands) != 0 {
+		usageLine = append(usageLine, "[command]")
+	}
+	usageLine = append(usageLine, "[flags]")
+	for _, arg := range args {
+		usageLine = append(usageLine, arg.usageName())
+	}
+	fmt.Fprintln(usage, strings.Join(strings.Fields(strings.Join(usageLine, " ")), " "))
+
+	if len(commands) != 0 {
+		fmt.Fprintln(usage, "\nCommands:")
+		rows := [][2]string{}
+		for _, command := range commands {
+			rows = append(rows, [2]string{command.Name, command.Description})
+		}
+		writeUsageRows(usage, rows)
+	}
+
+	if len(args) != 0 {
+		fmt.Fprintln(usage, "\nArguments:")
+		rows := [][2]string{}
+		for _, arg := range args {
+			rows = append(rows, [2]string{arg.usageName(), withUsageDefault(arg.desc, arg.defaultValue)})
+		}
+		writeUsageRows(usage, rows)
+	}
+
+	fmt.Fprintln(usage, "\nFlags:")
+	for _, flag := range flags {
+		flagPath := flag.path
+		if commandPath := l.CommandPath(); commandPath != "" && path == commandPath && l.flagTarget.has(flag.path) {
+			flagPath = strings.TrimPrefix(flag.path, commandPath+".")
+		}
+
+		flagLine := usageIndent + "--" + pathToFlagName(flagPath) + " " + flag.typeName
+		if flag.isBool {
+			flagLine = usageIndent + "--[" + negatedFlagPrefix + "]" + pathToFlagName(flagPath)
+		}
+		fmt.Fprintln(usage, flagLine)
+		description := flag.desc
+		if len(flag.values) != 0 {
+			description = strings.TrimSpace(description + " (one of " + strings.Join(flag.values, ", ") + ")")
+		}
+		if description := withUsageDefault(description, flag.defaultValue); description != "" {
+			fmt.Fprintln(usage, usageDetailIndent+description)
+		}
+		sourceNames := []string{}
+		if l.envPrefix != "" {
+			sourceNames = append(sourceNames, "env: "+pathToEnvName(l.envPrefix, flag.path))
+		}
+		sourceNames = append(sourceNames, "file: "+pathToFileKey(flag.path))
+		fmt.Fprintln(usage, usageDetailIndent+strings.Join(sourceNames, ", "))
+	}
+	fmt.Fprintln(usage, usageIndent+"-h, --help")
+	fmt.Fprintln(usage, usageDetailIndent+"Show this help")
+
+	return usage.String()
+}
+
+// collectUsage walks the fields of value the same way Get does, collecting the
+// paths of its fields and the fields bound to positional arguments. Slices of
+// structs are skipped as their paths are indexed.
+func collectUsage(currentPath string, value reflect.Value, structField reflect.StructField, flags *[]usageFlag, args *[]usageArg, seenTypes map[reflect.Type]bool) {
+	if !value.IsValid() {
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			if value.Kind() == reflect.Interface {
+				return
+			}
+			value = reflect.New(value.Type().Elem())
+		}
+		collectUsage(currentPath, value.Elem(), structField, flags, args, seenTypes)
+
+	case reflect.Struct:
+		typ := value.Type()
+		if seenTypes[typ] {
+			return
+		}
+		seenTypes[typ] = true
+		defer delete(seenTypes, typ)
+
+		for i := 0; i < typ.NumField(); i += 1 {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			tag, err := parseFieldTag(field)
+			if err != nil {
+				continue
+			}
+			if tag.isArg || tag.isArgs {
+				name := tag.name
+				if name == "" {
+					name = pathToFlagName(toCamelCase(calDefaultFieldTag(field.Name)))
+				}
+				*args = append(*args, usageArg{
+					name:         name,
+					tag:          tag,
+					defaultValue: usageDefault(value.Field(i)),
+					desc:         field.Tag.Get("desc"),
+				})
+				continue
+			}
+
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && tag.name == "" {
+				collectUsage(currentPath, value.Field(i), field, flags, args, seenTypes)
+				continue
+			}
+			fieldTag := tag.name
+			if fieldTag == "" {
+				fieldTag = calDefaultFieldTag(field.Name)
+			}
+			fieldPath := toCamelCase(fieldTag)
+			if currentPath != "" {
+				fieldPath = currentPath + "." + fieldPath
+			}
+			collectUsage(fieldPath, value.Field(i), field, flags, args, seenTypes)
+		}
+
+	case reflect.Slice:
+		elemType := value.Type().Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() == reflect.Struct || currentPath == "" {
+			return
+		}
+		*flags = append(*flags, usageFlag{
+			path:         currentPath,
+			typeName:     elemType.Kind().String() + " (repeatable)",
+			defaultValue: usageDefault(value),
+			desc:         structField.Tag.Get("desc"),
+			values:       enumValues(structField),
+		})
+
+	default:
+		if currentPath == "" {
+			return
+		}
+		*flags = append(*flags, usageFlag{
+			path:         currentPath,
+			typeName:     value.Kind().String(),
+			isBool:       value.Kind() == reflect.Bool,
+			defaultValue: usageDefault(value),
+			desc:         structField.Tag.Get("desc"),
+			values:       enumValues(structField),
+		})
+	}
+}
+
+// enumValues returns the values listed in the `enum` tag of a field.
+func enumValues(structField reflect.StructField) []string {
+	values := []string{}
+	for _, value := range strings.Split(structField.Tag.Get("enum"), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (a usageArg) usageName() string {
+	switch {
+	case a.tag.isArgs:
+		return "[" + a.name + "...]"
+	case a.tag.optional:
+		return "[" + a.name + "]"
+	default:
+		return "<" + a.name + ">"
+	}
+}
+
+// usageDefault formats the current value of a field, or returns an empty string
+// if the value is the zero value of its type.
+func usageDefault(value reflect.Value) string {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	if value.IsZero() || (value.Kind() == reflect.Slice && value.Len() == 0) {
+		return ""
+	}
+	if value.Kind() == reflect.String {
+		return fmt.Sprintf("%q", value.String())
+	}
+	return fmt.Sprintf("%v", value.Interface())
+}
+
+func withUsageDefault(desc string, defaultValue string) string {
+	if defaultValue == "" {
+		return desc
+	}
+	if desc == "" {
+		return "(default " + defaultValue + ")"
+	}
+	return desc + " (default " + defaultValue + ")"
+}
+
+func writeUsageRows(usage *strings.Builder, rows [][2]string) {
+	width := 0
+	for _, row := range rows {
+		if len(row[0]) > width {
+			width = len(row[0])
+		}
+	}
+	for _, row := range rows {
+		fmt.Fprintln(usage, strings.TrimRight(fmt.Sprintf("%s%-*s  %s", usageIndent, width, row[0], row[1]), " "))
+	}
+}
+
+// pathToFlagName converts a path into the flag which sets it, such as
+// `db--connection-pool-size` for `db.connectionPoolSize`.
+func pathToFlagName(path string) string {
+	return convertPathSegments(path, "--", func(segment string) string {
+		return strings.ToLower(splitCamelCase(segment, "-"))
+	})
+}
+
+// pathToEnvName converts a path into the environment variable which sets it,
+// such as `MY_APP__DB__CONNECTION_POOL_SIZE` for `db.connectionPoolSize`.
+func pathToEnvName(prefix string, path string) string {
+	return prefix + "__" + convertPathSegments(path, "__", func(segment string) string {
+		return strings.ToUpper(splitCamelCase(segment, "_"))
+	})
+}
+
+// pathToFileKey converts a path into a key in configuration files, such as
+// `db.connection_pool_size` for `db.connectionPoolSize`.
+func pathToFileKey(path string) string {
+	return convertPathSegments(path, ".", func(segment string) string {
+		return strings.ToLower(splitCamelCase(segment, "_"))
+	})
+}
+
+func convertPathSegments(path string, separator string, convert func(string) string) string {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		segments[i] = convert(segment)
+	}
+	return strings.Join(segments, separator)
+}
+
+// splitCamelCase inserts separator before each upper case letter of a camel
+// case string, other than the first.
+func splitCamelCase(s string, separator string) string {
+	split := &strings.Builder{}
+	for i, r := range s {
+		if i != 0 && unicode.IsUpper(r) {
+			split.WriteString(separator)
+		}
+		split.WriteRune(r)
+	}
+	return split.String()
+}
@@ -0,0 +1,212 @@
+package orale_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/RobertWHurst/orale"
+)
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	type MigrateConfig struct {
+		DryRun bool   `config:"dryRun" desc:"Print the migrations without applying them"`
+		Steps  int    `config:"steps" desc:"Number of migrations to apply"`
+		Target string `config:",arg=0,optional" desc:"Migration to migrate to"`
+	}
+	type Config struct {
+		Verbose bool `config:"verbose" desc:"Log more"`
+		Db      struct {
+			ConnectionPoolSize int      `config:"connectionPoolSize" desc:"Connections to keep open"`
+			Hosts              []string `config:"hosts"`
+			Replicas           []struct {
+				Host string `config:"host"`
+			} `config:"replicas"`
+		} `config:"db"`
+		Name string `config:"name"`
+	}
+
+	newCommands := func(migrateConf *MigrateConfig) []*orale.Command {
+		return []*orale.Command{
+			{Name: "serve", Description: "Start the server"},
+			{Name: "db", Description: "Manage the database", Commands: []*orale.Command{
+				{Name: "migrate", Target: migrateConf},
+			}},
+		}
+	}
+
+	t.Run("should describe every path of the target", func(t *testing.T) {
+		t.Parallel()
+
+		conf := Config{Name: "app"}
+		conf.Db.ConnectionPoolSize = 3
+		loader, err := orale.Load("myApp",
+			orale.WithArgs([]string{"--help"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+			orale.WithTarget(&conf),
+			orale.WithCommands(newCommands(&MigrateConfig{})...),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !loader.HelpRequested {
+			t.Fatal("expected help to be requested")
+		}
+		if len(loader.FlagValues()) != 0 {
+			t.Fatalf("expected --help not to be a flag value, got %v", loader.FlagValues())
+		}
+
+		expected := `Usage: my-app [command] [flags]
+
+Commands:
+  serve  Start the server
+  db     Manage the database
+
+Flags:
+  --[no-]verbose
+      Log more
+      env: MY_APP__VERBOSE, file: verbose
+  --db--connection-pool-size int
+      Connections to keep open (default 3)
+      env: MY_APP__DB__CONNECTION_POOL_SIZE, file: db.connection_pool_size
+  --db--hosts string (repeatable)
+      env: MY_APP__DB__HOSTS, file: db.hosts
+  --name string
+      (default "app")
+      env: MY_APP__NAME, file: name
+  -h, --help
+      Show this help
+`
+		if usage := loader.Usage("", &conf); usage != expected {
+			t.Fatalf("expected usage:\n%s\ngot:\n%s", expected, usage)
+		}
+	})
+
+	t.Run("should describe the selected command", func(t *testing.T) {
+		t.Parallel()
+
+		migrateConf := MigrateConfig{Steps: 1}
+		loader, err := orale.Load("myApp",
+			orale.WithArgs([]string{"db", "migrate", "-h"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+			orale.WithCommands(newCommands(&migrateConf)...),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !loader.HelpRequested {
+			t.Fatal("expected help to be requested")
+		}
+
+		expected := `Usage: my-app db migrate [flags] [target]
+
+Arguments:
+  [target]  Migration to migrate to
+
+Flags:
+  --[no-]dry-run
+      Print the migrations without applying them
+      env: MY_APP__DB__MIGRATE__DRY_RUN, file: db.migrate.dry_run
+  --steps int
+      Number of migrations to apply (default 1)
+      env: MY_APP__DB__MIGRATE__STEPS, file: db.migrate.steps
+  -h, --help
+      Show this help
+`
+		if usage := loader.Usage(loader.CommandPath(), &migrateConf); usage != expected {
+			t.Fatalf("expected usage:\n%s\ngot:\n%s", expected, usage)
+		}
+	})
+
+	t.Run("should describe the global target after a command is selected", func(t *testing.T) {
+		t.Parallel()
+
+		type GlobalConfig struct {
+			Verbose bool `config:"verbose" desc:"Log more"`
+		}
+
+		conf := GlobalConfig{}
+		loader, err := orale.Load("myApp",
+			orale.WithArgs([]string{"db", "migrate", "-h"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+			orale.WithTarget(&conf),
+			orale.WithCommands(newCommands(&MigrateConfig{})...),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `Usage: my-app [command] [flags]
+
+Commands:
+  serve  Start the server
+  db     Manage the database
+
+Flags:
+  --[no-]verbose
+      Log more
+      env: MY_APP__VERBOSE, file: verbose
+  -h, --help
+      Show this help
+`
+		if usage := loader.Usage("", &conf); usage != expected {
+			t.Fatalf("expected usage:\n%s\ngot:\n%s", expected, usage)
+		}
+	})
+
+	t.Run("should list enum values", func(t *testing.T) {
+		t.Parallel()
+
+		type LogConfig struct {
+			LogLevel string `config:"logLevel" desc:"Log level" enum:"debug, info,warn"`
+		}
+
+		conf := LogConfig{LogLevel: "info"}
+		loader, err := orale.Load("myApp",
+			orale.WithArgs([]string{}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+			orale.WithTarget(&conf),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "  --log-level string\n      Log level (one of debug, info, warn) (default \"info\")\n"
+		if usage := loader.Usage("", &conf); !strings.Contains(usage, expected) {
+			t.Fatalf("expected usage to contain:\n%s\ngot:\n%s", expected, usage)
+		}
+	})
+
+	t.Run("should not intercept help flags the target defines", func(t *testing.T) {
+		t.Parallel()
+
+		type HostConfig struct {
+			Host string `config:"h"`
+		}
+
+		loader, err := orale.Load("myApp",
+			orale.WithArgs([]string{"-h", "localhost"}),
+			orale.WithEnviron([]string{}),
+			orale.WithFS(fstest.MapFS{}),
+			orale.WithTarget(&HostConfig{}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if loader.HelpRequested {
+			t.Fatal("expected help not to be requested")
+		}
+		if loader.FlagValues()["h"][0] != "localhost" {
+			t.Fatalf("expected h to be localhost, got %v", loader.FlagValues()["h"])
+		}
+	})
+}